@@ -0,0 +1,62 @@
+package trimet
+
+import "strconv"
+
+// An Alert contains information about a service alert, such as a detour,
+// stop closure or system-wide disruption, in effect at the time the query
+// was made.
+type Alert struct {
+	// A unique identifier of the alert.
+	ID int `json:"id"`
+
+	// A short summary of the alert, suitable for use as a headline.
+	Header string `json:"header_text"`
+
+	// A plain text description of the alert.
+	Description string `json:"desc"`
+
+	// A link to further information about the alert, if any.
+	InfoLink string `json:"info_link_url"`
+
+	// Whether the alert applies to the whole system rather than to
+	// particular routes or stops.
+	SystemWide bool `json:"system_wide_flag"`
+
+	// Time the alert begins.
+	Begin *Time `json:"begin"`
+
+	// Time the alert ends, if known.
+	End *Time `json:"end"`
+
+	// Occurs for every route the alert is applicable.
+	Routes []Route `json:"route"`
+
+	// Occurs for every stop the alert is applicable.
+	Locations []Location `json:"location"`
+}
+
+// ToDetour converts the alert to a Detour, for callers which consume the
+// detours web service.  Header, info link, location and system-wide
+// information is not carried over.
+func (a *Alert) ToDetour() Detour {
+	return Detour{
+		ID:          strconv.Itoa(a.ID),
+		Begin:       a.Begin,
+		End:         a.End,
+		Description: a.Description,
+		Routes:      a.Routes,
+	}
+}
+
+// AlertsToDetours converts each of alerts to a Detour.
+func AlertsToDetours(alerts []Alert) []Detour {
+	if nil == alerts {
+		return nil
+	}
+
+	detours := make([]Detour, len(alerts))
+	for i := range alerts {
+		detours[i] = alerts[i].ToDetour()
+	}
+	return detours
+}
@@ -0,0 +1,75 @@
+package trimet
+
+import (
+	"context"
+	"fmt"
+)
+
+// AlertsService retrieves the service alerts currently in effect by route or
+// stop.
+//
+// TriMet API docs: http://developer.trimet.org/ws_docs/alerts_ws.shtml
+type AlertsService struct {
+	client *Client
+}
+
+type AlertsRequest struct {
+	Request
+
+	// If present results will contain only alerts applicable for the route
+	// numbers provided.
+	Routes []int `url:"routes,omitempty,comma"`
+
+	// If present results will contain only alerts applicable for the stops
+	// provided.
+	LocationIDs []int `url:"locIDs,omitempty,comma"`
+
+	// If true the info link of each alert is included in the results.
+	InfoLink bool `url:"infolink,omitempty"`
+}
+
+type AlertsResponse struct {
+	Response
+	Alerts []Alert `json:"alert"`
+}
+
+type alertsResponseResults struct {
+	Results *AlertsResponse `json:"resultSet,omitempty"`
+}
+
+// Validate checks the request's route numbers and location IDs.
+func (r *AlertsRequest) Validate() error {
+	v := new(ValidationError)
+	for _, route := range r.Routes {
+		if route < 0 {
+			v.add("Routes", fmt.Sprintf("invalid route number %d", route))
+		}
+	}
+	for _, id := range r.LocationIDs {
+		if id <= 0 {
+			v.add("LocationIDs", fmt.Sprintf("invalid location ID %d", id))
+		}
+	}
+	return v.err()
+}
+
+// Get latest service alerts.
+func (s *AlertsService) Get(r *AlertsRequest) (*AlertsResponse, error) {
+	return s.GetContext(context.Background(), r)
+}
+
+// GetContext gets latest service alerts, aborting if ctx is done.
+func (s *AlertsService) GetContext(ctx context.Context, r *AlertsRequest) (*AlertsResponse, error) {
+	u, err := s.client.BaseURLV2.Parse("alerts")
+	if nil != err {
+		return nil, err
+	}
+
+	response := new(alertsResponseResults)
+	err = s.client.GetContext(ctx, u.String(), r, response)
+	if nil != err {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
@@ -0,0 +1,114 @@
+package trimet
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAlertsService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/V2/alerts", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"appID":    testAppID,
+			"json":     "true",
+			"routes":   "12",
+			"locIDs":   "4305",
+			"infolink": "true",
+		})
+		b, err := ioutil.ReadFile("testdata/V2/alerts.json")
+		if nil != err {
+			t.Fatal("Unable to read testdata/V2/alerts.json")
+		}
+		w.Write(b)
+	})
+
+	req := &AlertsRequest{
+		Routes:      []int{12},
+		LocationIDs: []int{4305},
+		InfoLink:    true,
+	}
+	alerts, err := client.Alerts.Get(req)
+	if err != nil {
+		t.Fatalf("Alerts.Get returned error: %v", err)
+	}
+
+	ms := func(ms int64) *Time {
+		return NewTime(time.Unix(0, ms*int64(time.Millisecond)))
+	}
+	expect := &AlertsResponse{
+		Response: Response{QueryTime: ms(1389575529351)},
+		Alerts: []Alert{
+			{
+				ID:          28997,
+				Header:      "No service to SW Pacific Hwy & 78th",
+				Description: "No service to SW Pacific Hwy & 78th (Stop ID 4305) due to construction. Use stops before or after.",
+				InfoLink:    "http://trimet.org/alerts/",
+				Begin:       ms(1384207620000),
+				End:         ms(2141287200000),
+				Routes: []Route{
+					{ID: 12, Description: "12-Barbur/Sandy Blvd", Type: "B", Detour: true},
+				},
+				Locations: []Location{
+					{ID: 4305, Description: "SW Pacific Hwy & 78th", Direction: "Southbound", Lat: 45.4397049, Lon: -122.7464911},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(alerts, expect) {
+		t.Errorf("Expected Alerts.Get to return:\n%+v\nfound:\n%+v", expect, alerts)
+	}
+
+	detours := AlertsToDetours(alerts.Alerts)
+	expectDetours := []Detour{
+		{
+			ID:          "28997",
+			Begin:       ms(1384207620000),
+			End:         ms(2141287200000),
+			Description: expect.Alerts[0].Description,
+			Routes:      expect.Alerts[0].Routes,
+		},
+	}
+	if !reflect.DeepEqual(detours, expectDetours) {
+		t.Errorf("Expected AlertsToDetours to return:\n%+v\nfound:\n%+v", expectDetours, detours)
+	}
+}
+
+func TestAlertsService_Get_jsonOnly(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/V2/alerts", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{
+			"appID": testAppID,
+			"json":  "true",
+		})
+		testHeader(t, r, "Accept", mediaType)
+		b, err := ioutil.ReadFile("testdata/V2/alerts.json")
+		if nil != err {
+			t.Fatal("Unable to read testdata/V2/alerts.json")
+		}
+		w.Write(b)
+	})
+
+	req := &AlertsRequest{}
+	req.XML = true
+	response, err := client.Alerts.Get(req)
+	if nil != err {
+		t.Fatalf("Alerts.Get returned error: %v", err)
+	}
+	if nil == response || 0 == len(response.Alerts) {
+		t.Errorf("Expected alerts decoded from JSON, found %+v", response)
+	}
+}
+
+func TestAlertsRequest_Validate(t *testing.T) {
+	testValidationFields(t, (&AlertsRequest{}).Validate())
+	testValidationFields(t, (&AlertsRequest{LocationIDs: []int{0}}).Validate(), "LocationIDs")
+	testValidationFields(t, (&AlertsRequest{Routes: []int{-1}}).Validate(), "Routes")
+}
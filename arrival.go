@@ -3,24 +3,24 @@ package trimet
 // Arrival contains arrival details for a Location.
 type Arrival struct {
 	// The Location id of the arrival.
-	Location int `json:"locid"`
+	Location int `json:"locid" xml:"locid,attr"`
 
 	// The block of the arrival.
-	Block int `json:"block"`
+	Block int `json:"block" xml:"block,attr"`
 
 	// The route number of the arrival.
-	Route int `json:"route"`
+	Route int `json:"route" xml:"route,attr"`
 
 	// Indicates if the vehicle has begun the trip which will arrive at the
 	// indicated stop.
-	Departed bool `json:"departed"`
+	Departed bool `json:"departed" xml:"departed,attr"`
 
 	// Indicates if the arrival may be effected by a detour in effect along the
 	// route.
-	Detour bool `json:"detour"`
+	Detour bool `json:"detour" xml:"detour,attr"`
 
 	// The direction of the route for this arrival.
-	Direction int `json:"dir"`
+	Direction int `json:"dir" xml:"dir,attr"`
 
 	// Current status of the service.
 	//
@@ -33,36 +33,36 @@ type Arrival struct {
 	//       when further than an hour away.
 	//     delayed: Status of service is uncertain.
 	//     canceled: Scheduled arrival was canceled for the day.
-	Status string `json:"status"`
+	Status string `json:"status" xml:"status,attr"`
 
 	// The estimated time for this arrival. If this value is not present the
 	// arrival could not be estimated and schedule is shown instead.
-	Estimated *Time `json:"estimated"`
+	Estimated *Time `json:"estimated" xml:"estimated,attr"`
 
 	// The scheduled stop time (or interpolated scheduled stop time when the
 	// stop is not a time point) of the arrival.
-	Scheduled *Time `json:"scheduled"`
+	Scheduled *Time `json:"scheduled" xml:"scheduled,attr"`
 
 	// The full text of the overhead sign of the vehicle when it arrives at the
 	// stop.
-	FullSign string `json:"fullsign"`
+	FullSign string `json:"fullsign" xml:"fullSign,attr"`
 
 	// The short version of text from the overhead sign of the vehicle when it
 	// arrives at the stop.
-	ShortSign string `json:"shortsign"`
+	ShortSign string `json:"shortsign" xml:"shortSign,attr"`
 
 	// The piece of the block for this arrival.
-	Piece string `json:"piece"`
+	Piece string `json:"piece" xml:"piece,attr"`
 
 	// The last known position of the vehicle along its block. Includes path
 	// information from this position to the indicated stop.
-	BlockPosition Position `json:"blockPosition"`
+	BlockPosition Position `json:"blockPosition" xml:"blockPosition"`
 
 	// Indicates conditions are influencing the reporting of arrivals for a
 	// route. This occurs in inclement weather conditions.
 	RouteStatus struct {
 		// Route number of this status.
-		Route int `json:"route"`
+		Route int `json:"route" xml:"route,attr"`
 
 		// The most current reported status.
 		//
@@ -74,6 +74,6 @@ type Arrival struct {
 		//       when conditions such as snow and ice cause vehicles along the
 		//       route to travel off their trip patterns. In such cases
 		//       predictions are highly inaccurate or impossible.
-		Status string `json:"status"`
-	} `json:"routeStatus"`
+		Status string `json:"status" xml:"status,attr"`
+	} `json:"routeStatus" xml:"routeStatus"`
 }
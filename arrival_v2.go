@@ -0,0 +1,80 @@
+package trimet
+
+// ArrivalV2 contains arrival details reported by the version 2 arrivals
+// service.
+type ArrivalV2 struct {
+	// A unique identifier of the arrival.
+	ID string `json:"id"`
+
+	// The Location id of the arrival.
+	Location int `json:"locid"`
+
+	// The route number of the arrival.
+	Route int `json:"route"`
+
+	// The direction of the route for this arrival.
+	Direction int `json:"dir"`
+
+	// The block of the arrival.
+	Block int `json:"blockID"`
+
+	// The trip of the arrival.
+	TripID string `json:"tripID"`
+
+	// The ID of the vehicle serving the arrival, if known.
+	VehicleID string `json:"vehicleID"`
+
+	// Current status of the service.  Has the same values as
+	// Arrival.Status.
+	Status string `json:"status"`
+
+	// The estimated time for this arrival, if available.
+	Estimated *Time `json:"estimated"`
+
+	// The scheduled stop time of the arrival.
+	Scheduled *Time `json:"scheduled"`
+
+	// Indicates if the vehicle has begun the trip which will arrive at the
+	// indicated stop.
+	Departed bool `json:"departed"`
+
+	// Indicates if the arrival is affected by a detour.
+	Detoured bool `json:"detoured"`
+
+	// The IDs of the detours affecting the arrival.
+	Detours []int `json:"detour"`
+
+	// The full text of the overhead sign of the vehicle when it arrives at
+	// the stop.
+	FullSign string `json:"fullSign"`
+
+	// The short version of text from the overhead sign.
+	ShortSign string `json:"shortSign"`
+
+	// The piece of the block for this arrival.
+	Piece string `json:"piece"`
+
+	// Number of feet the vehicle is away from the stop.
+	Feet Distance `json:"feet"`
+
+	// Indicates the vehicle is traveling in congested traffic.
+	InCongestion bool `json:"inCongestion"`
+
+	// How full the vehicle is, as a percentage of its capacity.
+	LoadPercentage int `json:"loadPercentage"`
+
+	// Indicates the vehicle only drops off riders at this stop.
+	DropOffOnly bool `json:"dropOffOnly"`
+
+	// Indicates this arrival begins a new trip of the block, as when a
+	// vehicle is interlined from another route.
+	NewTrip bool `json:"newTrip"`
+
+	// The route the vehicle serves before it is interlined onto this
+	// route, if any.
+	InterlinedRoute int `json:"interlinedRoute"`
+
+	// The last known position of the vehicle.  Only reported when
+	// ArrivalsV2Request.ShowPosition is set.
+	BlockPosition *Position `json:"blockPosition"`
+}
@@ -1,10 +1,21 @@
 package trimet
 
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
 // ArrivalsService reports next arrivals at a stop identified by location ID.
 //
 // TriMet API docs: http://developer.trimet.org/ws_docs/arrivals_ws.shtml
 type ArrivalsService struct {
 	client *Client
+
+	// Maximum number of concurrent requests made when a request for more
+	// than MaxLocationIDs locations is split up.  Defaults to
+	// DefaultParallelism if not positive.
+	Parallelism int
 }
 
 type ArrivalsRequest struct {
@@ -13,8 +24,9 @@ type ArrivalsRequest struct {
 	// The location IDs for which to report arrivals.
 	//
 	// Arrivals are reported for each unique route and direction that services
-	// each stop identified by their location ID. Up to 10 location IDs can be
-	// reported at once.
+	// each stop identified by their location ID. Up to MaxLocationIDs
+	// location IDs can be reported at once by TriMet; larger requests are
+	// split up by the ArrivalsService and their results merged.
 	LocationIDs []int `url:"locIDs,comma"`
 
 	// If true, NextBus API results will be included for those location IDs
@@ -32,18 +44,66 @@ type ArrivalsRequest struct {
 
 type ArrivalsResponse struct {
 	Response
-	Locations []Location `json:"location"`
-	Arrivals  []Arrival  `json:"arrival"`
+	Locations []Location `json:"location" xml:"location"`
+	Arrivals  []Arrival  `json:"arrival" xml:"arrival"`
 }
 
 type arrivalsResponseResults struct {
 	Results *ArrivalsResponse `json:"resultSet,omitempty"`
 }
 
+// UnmarshalXML decodes the XML result set, which is the document root.
+func (r *arrivalsResponseResults) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	r.Results = new(ArrivalsResponse)
+	return d.DecodeElement(r.Results, &start)
+}
+
+// Validate checks that the request can be answered by TriMet in a single
+// call.  The ArrivalsService splits requests for more than MaxLocationIDs
+// locations before validating each part.
+func (r *ArrivalsRequest) Validate() error {
+	v := new(ValidationError)
+	switch n := len(r.LocationIDs); {
+	case 0 == n:
+		v.add("LocationIDs", "at least one location ID is required")
+	case n > MaxLocationIDs:
+		v.add("LocationIDs", fmt.Sprintf("at most %d location IDs are allowed, found %d",
+			MaxLocationIDs, n)).Err = ErrTooManyLocations
+	}
+	r.validateLocationIDs(v)
+	return v.err()
+}
+
+// validateLocationIDs adds an error to v for each invalid location ID in r.
+func (r *ArrivalsRequest) validateLocationIDs(v *ValidationError) {
+	for _, id := range r.LocationIDs {
+		if id <= 0 {
+			v.add("LocationIDs", fmt.Sprintf("invalid location ID %d", id))
+		}
+	}
+}
+
 // Get latest arrival information.
 func (s *ArrivalsService) Get(r *ArrivalsRequest) (*ArrivalsResponse, error) {
+	return s.GetContext(context.Background(), r)
+}
+
+// GetContext gets latest arrival information, aborting if ctx is done.
+//
+// Requests for more than MaxLocationIDs locations are sent in several
+// concurrent requests.  If only some of those fail, the merged results of the
+// rest are returned along with a *PartialArrivalsError.
+func (s *ArrivalsService) GetContext(ctx context.Context, r *ArrivalsRequest) (*ArrivalsResponse, error) {
+	if nil != r && len(r.LocationIDs) > MaxLocationIDs {
+		return s.getFanOut(ctx, r)
+	}
+	return s.get(ctx, r)
+}
+
+// get sends a single arrivals request.
+func (s *ArrivalsService) get(ctx context.Context, r *ArrivalsRequest) (*ArrivalsResponse, error) {
 	response := new(arrivalsResponseResults)
-	err := s.client.Get("arrivals", r, response)
+	err := s.client.GetContext(ctx, "arrivals", r, response)
 	if nil != err {
 		return nil, err
 	}
@@ -0,0 +1,160 @@
+package trimet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// MaxLocationIDs is the maximum number of location IDs TriMet reports
+	// arrivals for in a single request.
+	MaxLocationIDs = 10
+
+	// DefaultParallelism is the default number of concurrent requests made
+	// by an ArrivalsService when splitting up large requests.
+	DefaultParallelism = 4
+)
+
+// A PartialArrivalsError reports the requests which failed when a request
+// for many locations was split up.
+type PartialArrivalsError struct {
+	// The failed requests, in the order their location IDs were requested.
+	Failures []ArrivalsFailure
+}
+
+// An ArrivalsFailure reports the failure of a request for a group of
+// location IDs.
+type ArrivalsFailure struct {
+	LocationIDs []int
+	Err         error
+}
+
+func (e *PartialArrivalsError) Error() string {
+	messages := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		messages[i] = fmt.Sprintf("locIDs %v: %v", f.LocationIDs, f.Err)
+	}
+	return fmt.Sprintf("%d arrivals request(s) failed: %s",
+		len(e.Failures), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the errors of the failed requests.
+func (e *PartialArrivalsError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// FailedLocationIDs returns the location IDs for which arrivals could not be
+// retrieved.
+func (e *PartialArrivalsError) FailedLocationIDs() []int {
+	var ids []int
+	for _, f := range e.Failures {
+		ids = append(ids, f.LocationIDs...)
+	}
+	return ids
+}
+
+// chunkLocationIDs splits ids into groups of at most size.
+func chunkLocationIDs(ids []int, size int) [][]int {
+	var chunks [][]int
+	for len(ids) > size {
+		chunks = append(chunks, ids[:size:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
+
+// getFanOut splits r into requests of at most MaxLocationIDs locations, sends
+// them concurrently and merges their results.  The location IDs are validated
+// before r is split, so that an invalid ID fails the whole request.
+func (s *ArrivalsService) getFanOut(ctx context.Context, r *ArrivalsRequest) (*ArrivalsResponse, error) {
+	v := new(ValidationError)
+	r.validateLocationIDs(v)
+	if err := v.err(); nil != err {
+		return nil, err
+	}
+
+	chunks := chunkLocationIDs(r.LocationIDs, MaxLocationIDs)
+	responses := make([]*ArrivalsResponse, len(chunks))
+	errs := make([]error, len(chunks))
+
+	parallelism := s.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+	sem := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+	for i, ids := range chunks {
+		chunk := *r
+		chunk.LocationIDs = ids
+
+		wg.Add(1)
+		go func(i int, chunk *ArrivalsRequest) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			responses[i], errs[i] = s.get(ctx, chunk)
+		}(i, &chunk)
+	}
+	wg.Wait()
+
+	return mergeArrivals(chunks, responses, errs)
+}
+
+// mergeArrivals combines the responses to split up requests.
+//
+// The merged QueryTime is the earliest of the responses, so that the merged
+// results are never considered more recent than any of their parts.
+func mergeArrivals(chunks [][]int, responses []*ArrivalsResponse, errs []error) (*ArrivalsResponse, error) {
+	merged := new(ArrivalsResponse)
+	partial := new(PartialArrivalsError)
+	succeeded := 0
+
+	for i, response := range responses {
+		if nil != errs[i] {
+			partial.Failures = append(partial.Failures, ArrivalsFailure{
+				LocationIDs: chunks[i],
+				Err:         errs[i],
+			})
+			continue
+		}
+
+		succeeded++
+		if nil == response {
+			continue
+		}
+
+		merged.Locations = append(merged.Locations, response.Locations...)
+		merged.Arrivals = append(merged.Arrivals, response.Arrivals...)
+
+		if qt := response.QueryTime; nil != qt && nil != qt.Time {
+			if nil == merged.QueryTime || qt.Before(*merged.QueryTime.Time) {
+				merged.QueryTime = qt
+			}
+		}
+	}
+
+	if 0 == len(partial.Failures) {
+		return merged, nil
+	}
+	if 0 == succeeded {
+		return nil, partial
+	}
+	return merged, partial
+}
@@ -0,0 +1,157 @@
+package trimet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// serveArrivalsByID responds to arrivals requests with one location and one
+// arrival per requested location ID.  Requests including failID fail.
+func serveArrivalsByID(t *testing.T, failID string) *[]string {
+	var mu sync.Mutex
+	var received []string
+
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		locIDs := r.FormValue("locIDs")
+		mu.Lock()
+		received = append(received, locIDs)
+		mu.Unlock()
+
+		ids := strings.Split(locIDs, ",")
+		if len(ids) > MaxLocationIDs {
+			t.Errorf("Expected at most %v locIDs, found %v", MaxLocationIDs, len(ids))
+		}
+
+		var locations, arrivals []string
+		for _, id := range ids {
+			if id == failID {
+				fmt.Fprintf(w, `{"resultSet":{"errorMessage":{"content":"Location id not found %s"}}}`, id)
+				return
+			}
+			locations = append(locations, fmt.Sprintf(`{"locid":%s}`, id))
+			arrivals = append(arrivals, fmt.Sprintf(`{"locid":%s}`, id))
+		}
+
+		queryTime := "2014-01-12T17:12:09.351-0800"
+		if "1" == ids[0] {
+			queryTime = "2014-01-12T17:12:08.000-0800"
+		}
+		fmt.Fprintf(w, `{"resultSet":{"location":[%s],"arrival":[%s],"queryTime":"%s"}}`,
+			strings.Join(locations, ","), strings.Join(arrivals, ","), queryTime)
+	})
+	return &received
+}
+
+func locationIDRange(from, to int) []int {
+	var ids []int
+	for id := from; id <= to; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestArrivalsService_Get_fanOut(t *testing.T) {
+	setup()
+	defer teardown()
+	received := serveArrivalsByID(t, "")
+
+	ids := locationIDRange(1, 25)
+	arrivals, err := client.Arrivals.Get(&ArrivalsRequest{LocationIDs: ids})
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error: %v", err)
+	}
+
+	if 3 != len(*received) {
+		t.Errorf("Expected 3 requests, found %v", *received)
+	}
+
+	var locIDs []int
+	for _, l := range arrivals.Locations {
+		locIDs = append(locIDs, l.ID)
+	}
+	if !reflect.DeepEqual(ids, locIDs) {
+		t.Errorf("Expected merged locations %v, found %v", ids, locIDs)
+	}
+	if len(ids) != len(arrivals.Arrivals) {
+		t.Errorf("Expected %v merged arrivals, found %v", len(ids), len(arrivals.Arrivals))
+	}
+
+	expect := newTestTime(t, "2014-01-12T17:12:08.000-0800")
+	if nil == arrivals.QueryTime || !expect.Equal(*arrivals.QueryTime.Time) {
+		t.Errorf("Expected earliest QueryTime %v, found %v", expect, arrivals.QueryTime)
+	}
+}
+
+func TestArrivalsService_Get_fanOutPartial(t *testing.T) {
+	setup()
+	defer teardown()
+	serveArrivalsByID(t, "15")
+
+	arrivals, err := client.Arrivals.Get(&ArrivalsRequest{LocationIDs: locationIDRange(1, 25)})
+
+	var partial *PartialArrivalsError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected *PartialArrivalsError, found %v", err)
+	}
+	if !errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("Expected %v to match ErrUnknownLocation", err)
+	}
+	if expect := locationIDRange(11, 20); !reflect.DeepEqual(expect, partial.FailedLocationIDs()) {
+		t.Errorf("Expected failed IDs %v, found %v", expect, partial.FailedLocationIDs())
+	}
+	if nil == arrivals || 15 != len(arrivals.Arrivals) {
+		t.Errorf("Expected 15 arrivals from successful requests, found %+v", arrivals)
+	}
+}
+
+func TestArrivalsService_Get_fanOutAllFailed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	})
+
+	arrivals, err := client.Arrivals.Get(&ArrivalsRequest{LocationIDs: locationIDRange(1, 11)})
+	if nil != arrivals {
+		t.Errorf("Expected nil response, found %+v", arrivals)
+	}
+	if !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("Expected ErrServerUnavailable, found %v", err)
+	}
+}
+
+func TestArrivalsService_Get_fanOutInvalid(t *testing.T) {
+	setup()
+	defer teardown()
+
+	received := serveArrivalsByID(t, "")
+
+	ids := append(locationIDRange(1, 14), -1)
+	arrivals, err := client.Arrivals.Get(&ArrivalsRequest{LocationIDs: ids})
+	if nil != arrivals {
+		t.Errorf("Expected nil response, found %+v", arrivals)
+	}
+	testValidationFields(t, err, "LocationIDs")
+
+	var partial *PartialArrivalsError
+	if errors.As(err, &partial) {
+		t.Errorf("Expected a validation error for the whole request, found %v", err)
+	}
+	if 0 != len(*received) {
+		t.Errorf("Expected no requests to be sent, found %v", *received)
+	}
+}
+
+func TestChunkLocationIDs(t *testing.T) {
+	chunks := chunkLocationIDs(locationIDRange(1, 12), 5)
+	expect := [][]int{{1, 2, 3, 4, 5}, {6, 7, 8, 9, 10}, {11, 12}}
+	if !reflect.DeepEqual(expect, chunks) {
+		t.Errorf("Expected chunks %v, found %v", expect, chunks)
+	}
+}
@@ -0,0 +1,385 @@
+package trimet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// An ArrivalsHub polls arrivals on behalf of many subscribers, so that the
+// number of requests made depends on the number of distinct stops watched
+// rather than the number of watchers.
+//
+// Each poll requests every subscribed location at once, which the
+// ArrivalsService splits into the fewest requests of MaxLocationIDs
+// locations, and delivers each subscriber the results for its own stops.
+// Newly subscribed stops are polled straight away, rather than waiting for
+// the next interval.
+type ArrivalsHub struct {
+	service  *ArrivalsService
+	request  ArrivalsRequest
+	interval time.Duration
+
+	mu      sync.Mutex
+	subs    map[*Subscription]struct{}
+	refs    map[int]int
+	pending map[int]bool
+	stops   map[int]*hubStop
+	wake    chan struct{}
+	stopped bool
+}
+
+// hubStop holds the latest arrivals polled at a location.
+type hubStop struct {
+	location  *Location
+	arrivals  []Arrival
+	queryTime *Time
+}
+
+// An ArrivalsUpdate is the result of a poll for a subscriber's stops.
+type ArrivalsUpdate struct {
+	// The latest arrivals at the subscriber's stops, or nil if none of them
+	// have been polled successfully.  Its QueryTime is that of the oldest
+	// results included.
+	Response *ArrivalsResponse
+
+	// The error of the poll, if it failed for any of the subscriber's stops.
+	Err error
+}
+
+// A Subscription receives the arrivals at a set of stops from an
+// ArrivalsHub.
+type Subscription struct {
+	// Updates delivers the results of each poll of the subscribed stops.
+	// Only the latest update is kept for a subscriber which falls behind.
+	// The channel is closed by Unsubscribe, or when the hub stops.
+	Updates <-chan ArrivalsUpdate
+
+	hub       *ArrivalsHub
+	updates   chan ArrivalsUpdate
+	locations map[int]bool
+	closed    bool
+}
+
+// NewArrivalsHub returns an ArrivalsHub polling with s every interval, or
+// DefaultWatchInterval if interval is not positive.  Other request options,
+// such as Streetcar, are taken from r, which may be nil.
+func NewArrivalsHub(s *ArrivalsService, r *ArrivalsRequest, interval time.Duration) *ArrivalsHub {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	h := &ArrivalsHub{
+		service:  s,
+		interval: interval,
+		subs:     make(map[*Subscription]struct{}),
+		refs:     make(map[int]int),
+		pending:  make(map[int]bool),
+		stops:    make(map[int]*hubStop),
+		wake:     make(chan struct{}, 1),
+	}
+	if nil != r {
+		h.request = *r
+	}
+	return h
+}
+
+// Subscribe returns a Subscription to the arrivals at locationIDs.  If all
+// of them have already been polled for other subscribers, their latest
+// arrivals are delivered straight away.  Once Run has returned, the
+// subscription is returned already closed.
+func (h *ArrivalsHub) Subscribe(locationIDs ...int) *Subscription {
+	updates := make(chan ArrivalsUpdate, 1)
+	sub := &Subscription{
+		Updates:   updates,
+		hub:       h,
+		updates:   updates,
+		locations: make(map[int]bool),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopped {
+		sub.closed = true
+		close(sub.updates)
+		return sub
+	}
+	h.subs[sub] = struct{}{}
+	h.addLocations(sub, locationIDs)
+
+	for id := range sub.locations {
+		if _, ok := h.stops[id]; !ok {
+			return sub
+		}
+	}
+	if 0 != len(sub.locations) {
+		update, _ := h.update(sub, nil, sub.locations, nil)
+		sub.deliver(update)
+	}
+	return sub
+}
+
+// Locations returns the subscribed location IDs, in ascending order.
+func (sub *Subscription) Locations() []int {
+	sub.hub.mu.Lock()
+	defer sub.hub.mu.Unlock()
+	return sortedIDs(sub.locations)
+}
+
+// Add subscribes to the arrivals at further locationIDs.
+func (sub *Subscription) Add(locationIDs ...int) {
+	sub.hub.mu.Lock()
+	if !sub.closed {
+		sub.hub.addLocations(sub, locationIDs)
+	}
+	sub.hub.mu.Unlock()
+}
+
+// Remove unsubscribes from the arrivals at locationIDs.
+func (sub *Subscription) Remove(locationIDs ...int) {
+	sub.hub.mu.Lock()
+	if !sub.closed {
+		sub.hub.removeLocations(sub, locationIDs)
+	}
+	sub.hub.mu.Unlock()
+}
+
+// Unsubscribe ends the subscription and closes its Updates channel.
+func (sub *Subscription) Unsubscribe() {
+	h := sub.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sub.closed {
+		return
+	}
+	h.removeLocations(sub, sortedIDs(sub.locations))
+	delete(h.subs, sub)
+	sub.closed = true
+	close(sub.updates)
+}
+
+// addLocations adds locationIDs to sub, marking locations not yet polled
+// for an immediate poll.  The caller must hold h.mu.
+func (h *ArrivalsHub) addLocations(sub *Subscription, locationIDs []int) {
+	added := false
+	for _, id := range locationIDs {
+		if sub.locations[id] {
+			continue
+		}
+		sub.locations[id] = true
+		if h.refs[id]++; 1 == h.refs[id] {
+			h.pending[id] = true
+			added = true
+		}
+	}
+	if added {
+		select {
+		case h.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// removeLocations removes locationIDs from sub.  The caller must hold h.mu.
+func (h *ArrivalsHub) removeLocations(sub *Subscription, locationIDs []int) {
+	for _, id := range locationIDs {
+		if !sub.locations[id] {
+			continue
+		}
+		delete(sub.locations, id)
+		if h.refs[id]--; 0 == h.refs[id] {
+			delete(h.refs, id)
+			delete(h.pending, id)
+			delete(h.stops, id)
+		}
+	}
+}
+
+// Locations returns the distinct location IDs of every subscription, in
+// ascending order.
+func (h *ArrivalsHub) Locations() []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.locationIDs()
+}
+
+// Run polls for arrivals at the subscribed locations until ctx is done, then
+// closes every subscription and returns the error of ctx.  The hub is
+// stopped for good: later subscriptions are closed straight away.
+func (h *ArrivalsHub) Run(ctx context.Context) error {
+	defer h.closeAll()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	all := true
+	for {
+		var locationIDs []int
+		h.mu.Lock()
+		if all {
+			locationIDs = h.locationIDs()
+		} else {
+			locationIDs = sortedIDs(h.pending)
+		}
+		h.pending = make(map[int]bool)
+		h.mu.Unlock()
+
+		if 0 != len(locationIDs) {
+			h.poll(ctx, locationIDs)
+		}
+
+		select {
+		case <-ticker.C:
+			all = true
+		case <-h.wake:
+			all = false
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// poll requests arrivals at locationIDs and delivers them to subscribers.
+func (h *ArrivalsHub) poll(ctx context.Context, locationIDs []int) {
+	req := h.request
+	req.LocationIDs = locationIDs
+
+	response, err := h.service.GetContext(ctx, &req)
+	if nil != ctx.Err() {
+		return
+	}
+
+	polled := make(map[int]bool, len(locationIDs))
+	for _, id := range locationIDs {
+		polled[id] = true
+	}
+	failed := make(map[int]bool)
+	var partial *PartialArrivalsError
+	if errors.As(err, &partial) {
+		for _, id := range partial.FailedLocationIDs() {
+			failed[id] = true
+		}
+	} else if nil != err {
+		failed = polled
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.store(response, polled, failed)
+	for sub := range h.subs {
+		if update, ok := h.update(sub, err, polled, failed); ok {
+			sub.deliver(update)
+		}
+	}
+}
+
+// store records the arrivals at the successfully polled locations which are
+// still subscribed.  The caller must hold h.mu.
+func (h *ArrivalsHub) store(response *ArrivalsResponse, polled, failed map[int]bool) {
+	if nil == response {
+		return
+	}
+	for id := range polled {
+		if !failed[id] && 0 != h.refs[id] {
+			h.stops[id] = &hubStop{queryTime: response.QueryTime}
+		}
+	}
+	for i := range response.Locations {
+		if stop, ok := h.stops[response.Locations[i].ID]; ok && polled[response.Locations[i].ID] {
+			stop.location = &response.Locations[i]
+		}
+	}
+	for _, a := range response.Arrivals {
+		if stop, ok := h.stops[a.Location]; ok && polled[a.Location] {
+			stop.arrivals = append(stop.arrivals, a)
+		}
+	}
+}
+
+// update returns the update for sub after a poll of the polled locations,
+// and whether any of them are subscribed by sub.  The update holds the
+// latest arrivals at all of the subscriber's stops, with the QueryTime of
+// the oldest.  The caller must hold h.mu.
+func (h *ArrivalsHub) update(sub *Subscription, err error, polled, failed map[int]bool) (ArrivalsUpdate, bool) {
+	var update ArrivalsUpdate
+	relevant := false
+	for id := range sub.locations {
+		if polled[id] {
+			relevant = true
+		}
+		if failed[id] {
+			update.Err = err
+		}
+	}
+	if !relevant {
+		return update, false
+	}
+
+	for _, id := range sortedIDs(sub.locations) {
+		stop, ok := h.stops[id]
+		if !ok {
+			continue
+		}
+		if nil == update.Response {
+			update.Response = new(ArrivalsResponse)
+		}
+		if nil != stop.location {
+			update.Response.Locations = append(update.Response.Locations, *stop.location)
+		}
+		update.Response.Arrivals = append(update.Response.Arrivals, stop.arrivals...)
+		if qt := stop.queryTime; nil != qt && nil != qt.Time {
+			if nil == update.Response.QueryTime || qt.Before(*update.Response.QueryTime.Time) {
+				update.Response.QueryTime = qt
+			}
+		}
+	}
+	return update, true
+}
+
+// deliver sends update to sub, replacing any update it has not yet
+// received.  The caller must hold the hub's mu.
+func (sub *Subscription) deliver(update ArrivalsUpdate) {
+	select {
+	case <-sub.updates:
+	default:
+	}
+	sub.updates <- update
+}
+
+// closeAll stops the hub, ending every subscription.
+func (h *ArrivalsHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopped = true
+	for sub := range h.subs {
+		sub.closed = true
+		close(sub.updates)
+	}
+	h.subs = make(map[*Subscription]struct{})
+	h.refs = make(map[int]int)
+	h.pending = make(map[int]bool)
+	h.stops = make(map[int]*hubStop)
+}
+
+// sortedIDs returns the location IDs in ids, in ascending order.
+func sortedIDs(ids map[int]bool) []int {
+	sorted := make([]int, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+// locationIDs returns the subscribed location IDs, in ascending order.  The
+// caller must hold h.mu.
+func (h *ArrivalsHub) locationIDs() []int {
+	ids := make([]int, 0, len(h.refs))
+	for id := range h.refs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
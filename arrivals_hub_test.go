@@ -0,0 +1,141 @@
+package trimet
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// receiveUpdate waits for the next update of sub.
+func receiveUpdate(t *testing.T, sub *Subscription) ArrivalsUpdate {
+	select {
+	case update, ok := <-sub.Updates:
+		if !ok {
+			t.Fatal("Expected update, found Updates closed")
+		}
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for update")
+	}
+	return ArrivalsUpdate{}
+}
+
+// updateLocations returns the location IDs of the locations and arrivals of
+// update.
+func updateLocations(t *testing.T, update ArrivalsUpdate) []int {
+	if nil == update.Response {
+		t.Fatalf("Expected update response, found error %v", update.Err)
+	}
+	var ids []int
+	for i, l := range update.Response.Locations {
+		if l.ID != update.Response.Arrivals[i].Location {
+			t.Errorf("Expected arrival at %v, found %v", l.ID, update.Response.Arrivals[i].Location)
+		}
+		ids = append(ids, l.ID)
+	}
+	return ids
+}
+
+func TestArrivalsHub(t *testing.T) {
+	setup()
+	defer teardown()
+
+	received := serveArrivalsByID(t, "")
+
+	hub := NewArrivalsHub(client.Arrivals, nil, time.Hour)
+	a := hub.Subscribe(locationIDRange(1, 8)...)
+	b := hub.Subscribe(locationIDRange(5, 15)...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- hub.Run(ctx) }()
+
+	if ids := updateLocations(t, receiveUpdate(t, a)); !reflect.DeepEqual(locationIDRange(1, 8), ids) {
+		t.Errorf("Expected locations %v, found %v", locationIDRange(1, 8), ids)
+	}
+	if ids := updateLocations(t, receiveUpdate(t, b)); !reflect.DeepEqual(locationIDRange(5, 15), ids) {
+		t.Errorf("Expected locations %v, found %v", locationIDRange(5, 15), ids)
+	}
+	if 2 != len(*received) {
+		t.Errorf("Expected 15 locations in 2 requests, found %v", *received)
+	}
+
+	a.Add(20)
+	expect := append(locationIDRange(1, 8), 20)
+	if ids := updateLocations(t, receiveUpdate(t, a)); !reflect.DeepEqual(expect, ids) {
+		t.Errorf("Expected locations %v, found %v", expect, ids)
+	}
+	if 3 != len(*received) || "20" != (*received)[2] {
+		t.Errorf("Expected only the added location to be requested, found %v", *received)
+	}
+	select {
+	case update := <-b.Updates:
+		t.Errorf("Expected no update for unaffected subscription, found %+v", update)
+	default:
+	}
+
+	b.Unsubscribe()
+	if _, ok := <-b.Updates; ok {
+		t.Error("Expected Updates closed by Unsubscribe")
+	}
+	if locations := hub.Locations(); !reflect.DeepEqual(expect, locations) {
+		t.Errorf("Expected hub locations %v, found %v", expect, locations)
+	}
+
+	c := hub.Subscribe(5, 20)
+	if ids := updateLocations(t, receiveUpdate(t, c)); !reflect.DeepEqual([]int{5, 20}, ids) {
+		t.Errorf("Expected latest arrivals at [5 20], found %v", ids)
+	}
+	if 3 != len(*received) {
+		t.Errorf("Expected already polled locations not to be requested, found %v", *received)
+	}
+
+	cancel()
+	if err := <-done; context.Canceled != err {
+		t.Errorf("Expected Run to return %v, found %v", context.Canceled, err)
+	}
+	if _, ok := <-a.Updates; ok {
+		t.Error("Expected Updates closed when hub stops")
+	}
+	a.Remove(1, 2)
+	a.Add(3)
+	if ids := hub.Locations(); 0 != len(ids) {
+		t.Errorf("Expected no locations once the hub stops, found %v", ids)
+	}
+
+	if _, ok := <-hub.Subscribe(8989).Updates; ok {
+		t.Error("Expected Updates closed when subscribing to a stopped hub")
+	}
+}
+
+func TestArrivalsHub_partialError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	serveArrivalsByID(t, "14")
+
+	hub := NewArrivalsHub(client.Arrivals, nil, time.Hour)
+	a := hub.Subscribe(locationIDRange(1, 5)...)
+	b := hub.Subscribe(locationIDRange(8, 16)...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	update := receiveUpdate(t, a)
+	if nil != update.Err {
+		t.Errorf("Expected no error for locations which succeeded, found %v", update.Err)
+	}
+	if ids := updateLocations(t, update); !reflect.DeepEqual(locationIDRange(1, 5), ids) {
+		t.Errorf("Expected locations %v, found %v", locationIDRange(1, 5), ids)
+	}
+
+	update = receiveUpdate(t, b)
+	if _, ok := update.Err.(*PartialArrivalsError); !ok {
+		t.Errorf("Expected *PartialArrivalsError, found %v", update.Err)
+	}
+	if ids := updateLocations(t, update); !reflect.DeepEqual(locationIDRange(8, 12), ids) {
+		t.Errorf("Expected locations %v, found %v", locationIDRange(8, 12), ids)
+	}
+}
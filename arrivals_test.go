@@ -1,6 +1,7 @@
 package trimet
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"reflect"
@@ -102,3 +103,17 @@ func TestArrivalsService_Get_badRequest(t *testing.T) {
 		t.Error("Expected Arrivals.Get to return error for nil request")
 	}
 }
+
+func TestArrivalsService_GetContext_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := &ArrivalsRequest{LocationIDs: []int{8989}}
+	_, err := client.Arrivals.GetContext(ctx, req)
+	if context.Canceled != err {
+		t.Errorf("Expected Arrivals.GetContext to return context.Canceled, found %v", err)
+	}
+}
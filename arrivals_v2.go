@@ -0,0 +1,92 @@
+package trimet
+
+import (
+	"context"
+	"fmt"
+)
+
+// ArrivalsV2Service reports next arrivals at a stop using version 2 of the
+// arrivals web service, which adds vehicle, load and congestion information.
+//
+// TriMet API docs: http://developer.trimet.org/ws_docs/arrivals2_ws.shtml
+type ArrivalsV2Service struct {
+	client *Client
+}
+
+type ArrivalsV2Request struct {
+	Request
+
+	// The location IDs for which to report arrivals.  Up to MaxLocationIDs
+	// location IDs can be reported at once.
+	LocationIDs []int `url:"locIDs,comma"`
+
+	// Report arrivals up to this many minutes in the future.  TriMet
+	// defaults to 60.
+	Minutes int `url:"minutes,omitempty"`
+
+	// Report arrivals beginning this many minutes in the future.
+	MinutesAfter int `url:"minutesAfter,omitempty"`
+
+	// Report at most this many arrivals for each route at each stop.
+	Arrivals int `url:"arrivals,omitempty"`
+
+	// If true, the position of each vehicle is reported in its arrival's
+	// BlockPosition.
+	ShowPosition bool `url:"showPosition,omitempty"`
+
+	// If true, Portland Streetcar arrivals are included.
+	Streetcar bool `url:"streetcar,omitempty"`
+}
+
+type ArrivalsV2Response struct {
+	Response
+	Locations []Location  `json:"location"`
+	Arrivals  []ArrivalV2 `json:"arrival"`
+}
+
+type arrivalsV2ResponseResults struct {
+	Results *ArrivalsV2Response `json:"resultSet,omitempty"`
+}
+
+// Validate checks that the request can be answered by TriMet.
+func (r *ArrivalsV2Request) Validate() error {
+	v := new(ValidationError)
+	switch n := len(r.LocationIDs); {
+	case 0 == n:
+		v.add("LocationIDs", "at least one location ID is required")
+	case n > MaxLocationIDs:
+		v.add("LocationIDs", fmt.Sprintf("at most %d location IDs are allowed, found %d",
+			MaxLocationIDs, n)).Err = ErrTooManyLocations
+	}
+	if r.Minutes < 0 {
+		v.add("Minutes", "must not be negative")
+	}
+	if r.MinutesAfter < 0 {
+		v.add("MinutesAfter", "must not be negative")
+	}
+	if r.Arrivals < 0 {
+		v.add("Arrivals", "must not be negative")
+	}
+	return v.err()
+}
+
+// Get latest arrival information.
+func (s *ArrivalsV2Service) Get(r *ArrivalsV2Request) (*ArrivalsV2Response, error) {
+	return s.GetContext(context.Background(), r)
+}
+
+// GetContext gets latest arrival information, aborting if ctx is done.
+func (s *ArrivalsV2Service) GetContext(ctx context.Context, r *ArrivalsV2Request) (*ArrivalsV2Response, error) {
+	u, err := s.client.BaseURLV2.Parse("arrivals")
+	if nil != err {
+		return nil, err
+	}
+
+	response := new(arrivalsV2ResponseResults)
+	err = s.client.GetContext(ctx, u.String(), r, response)
+	if nil != err {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
@@ -0,0 +1,138 @@
+package trimet
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestArrivalsV2Service_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/V2/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"appID":        testAppID,
+			"json":         "true",
+			"locIDs":       "8989",
+			"minutes":      "30",
+			"arrivals":     "2",
+			"showPosition": "true",
+		})
+		b, err := ioutil.ReadFile("testdata/V2/arrivals.json")
+		if nil != err {
+			t.Fatal("Unable to read testdata/V2/arrivals.json")
+		}
+		w.Write(b)
+	})
+
+	req := &ArrivalsV2Request{
+		LocationIDs:  []int{8989},
+		Minutes:      30,
+		Arrivals:     2,
+		ShowPosition: true,
+	}
+	arrivals, err := client.ArrivalsV2.Get(req)
+	if err != nil {
+		t.Fatalf("ArrivalsV2.Get returned error: %v", err)
+	}
+
+	ms := func(ms int64) *Time {
+		return NewTime(time.Unix(0, ms*int64(time.Millisecond)))
+	}
+	expect := &ArrivalsV2Response{
+		Response: Response{QueryTime: ms(1389575529351)},
+		Locations: []Location{
+			{
+				ID:          8989,
+				Description: "NW 23rd & Marshall",
+				Direction:   "Southbound",
+				Lon:         -122.698688376761,
+				Lat:         45.5306116478909,
+			},
+		},
+		Arrivals: []ArrivalV2{
+			{
+				ID:              "4285964_60360_8989",
+				Location:        8989,
+				Route:           15,
+				Direction:       1,
+				Block:           1537,
+				TripID:          "4285964",
+				VehicleID:       "2904",
+				Status:          "estimated",
+				Scheduled:       ms(1389577560000),
+				Estimated:       ms(1389577620000),
+				Departed:        true,
+				Detoured:        true,
+				Detours:         []int{29416},
+				FullSign:        "15  Belmont/NW 23rd to Gateway TC",
+				ShortSign:       "15 Gateway TC",
+				Piece:           "1",
+				Feet:            15005,
+				LoadPercentage:  42,
+				NewTrip:         true,
+				InterlinedRoute: 77,
+				BlockPosition: &Position{
+					At:      ms(1389575525000),
+					Feet:    15005,
+					Lon:     -122.6973469,
+					Lat:     45.5233678,
+					Heading: 273,
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(arrivals, expect) {
+		t.Errorf("Expected ArrivalsV2.Get to return:\n%+v\nfound:\n%+v", expect, arrivals)
+	}
+
+	if usage := client.Usage(); 1 != usage["v2/arrivals"] {
+		t.Errorf("Expected usage to count v2/arrivals, found %v", usage)
+	}
+}
+
+func TestArrivalsV2Service_Get_jsonOnly(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/V2/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{
+			"appID":  testAppID,
+			"json":   "true",
+			"locIDs": "8989",
+		})
+		testHeader(t, r, "Accept", mediaType)
+		b, err := ioutil.ReadFile("testdata/V2/arrivals.json")
+		if nil != err {
+			t.Fatal("Unable to read testdata/V2/arrivals.json")
+		}
+		w.Write(b)
+	})
+
+	req := &ArrivalsV2Request{LocationIDs: []int{8989}}
+	req.XML = true
+	arrivals, err := client.ArrivalsV2.Get(req)
+	if nil != err {
+		t.Fatalf("ArrivalsV2.Get returned error: %v", err)
+	}
+	if nil == arrivals || 0 == len(arrivals.Arrivals) {
+		t.Errorf("Expected arrivals decoded from JSON, found %+v", arrivals)
+	}
+}
+
+func TestArrivalsV2Request_Validate(t *testing.T) {
+	testValidationFields(t, (&ArrivalsV2Request{LocationIDs: []int{8989}}).Validate())
+	testValidationFields(t, (&ArrivalsV2Request{}).Validate(), "LocationIDs")
+	testValidationFields(t, (&ArrivalsV2Request{LocationIDs: []int{8989}, Minutes: -1}).Validate(), "Minutes")
+}
+
+func TestClient_endpoint_v2(t *testing.T) {
+	c := NewClient(testAppID, nil)
+	if e := c.endpoint("/ws/V2/arrivals"); "v2/arrivals" != e {
+		t.Errorf("Expected endpoint = v2/arrivals, found %v", e)
+	}
+}
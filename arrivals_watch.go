@@ -0,0 +1,266 @@
+package trimet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// DefaultWatchInterval is the polling interval used by Watch when none is
+// given.
+const DefaultWatchInterval = 30 * time.Second
+
+// An ArrivalEventType describes how an arrival changed between polls.
+type ArrivalEventType int
+
+const (
+	// ArrivalAppeared reports an arrival not seen in the previous poll.
+	ArrivalAppeared ArrivalEventType = iota + 1
+
+	// ArrivalETAChanged reports a change in the estimated time of an
+	// arrival.
+	ArrivalETAChanged
+
+	// ArrivalStatusChanged reports a change in the status of an arrival,
+	// such as from "scheduled" to "estimated", or to "delayed" or
+	// "canceled".
+	ArrivalStatusChanged
+
+	// ArrivalDeparted reports that the vehicle has served the stop: the
+	// arrival is no longer reported, and either the vehicle was last
+	// reported as Departed on its trip to the stop or its expected time
+	// has passed.
+	ArrivalDeparted
+
+	// ArrivalDropped reports an arrival which is no longer reported before
+	// its expected time, and whose vehicle had not begun its trip.
+	ArrivalDropped
+
+	// ArrivalError reports a failed poll.  Watching continues, and the
+	// next successful poll is compared with the last successful one.
+	ArrivalError
+
+	// ArrivalVehicleDeparted reports that the vehicle of an arrival still
+	// reported has begun its trip to the stop: Departed became true.
+	ArrivalVehicleDeparted
+)
+
+var arrivalEventTypes = map[ArrivalEventType]string{
+	ArrivalAppeared:        "appeared",
+	ArrivalETAChanged:      "eta changed",
+	ArrivalStatusChanged:   "status changed",
+	ArrivalDeparted:        "departed",
+	ArrivalDropped:         "dropped",
+	ArrivalError:           "error",
+	ArrivalVehicleDeparted: "vehicle departed",
+}
+
+func (t ArrivalEventType) String() string {
+	if s, ok := arrivalEventTypes[t]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// An ArrivalKey identifies an arrival across polls.
+type ArrivalKey struct {
+	Location  int
+	Route     int
+	Direction int
+	Block     int
+
+	// The scheduled time of the arrival, in UTC.
+	Scheduled time.Time
+}
+
+// NewArrivalKey returns the key identifying a.
+func NewArrivalKey(a *Arrival) ArrivalKey {
+	k := ArrivalKey{
+		Location:  a.Location,
+		Route:     a.Route,
+		Direction: a.Direction,
+		Block:     a.Block,
+	}
+	if nil != a.Scheduled && nil != a.Scheduled.Time {
+		k.Scheduled = a.Scheduled.UTC()
+	}
+	return k
+}
+
+// An ArrivalEvent is a change observed by Watch.
+type ArrivalEvent struct {
+	Type ArrivalEventType
+	Key  ArrivalKey
+
+	// The arrival as currently reported, or as last reported for departed
+	// and dropped arrivals.  Nil for errors.
+	Arrival *Arrival
+
+	// The arrival as previously reported, for changes.
+	Previous *Arrival
+
+	// The error of a failed poll.
+	Err error
+
+	// The query time of the poll which observed the event.
+	At time.Time
+}
+
+// Watch polls for arrivals every interval, or DefaultWatchInterval if
+// interval is not positive, and reports how they change on the returned
+// channel.  Every arrival is reported as appeared by the first poll.
+//
+// Failed polls are reported as ArrivalError events rather than stopping the
+// watch.  The channel is closed once ctx is done.
+func (s *ArrivalsService) Watch(ctx context.Context, r *ArrivalsRequest, interval time.Duration) <-chan ArrivalEvent {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	events := make(chan ArrivalEvent)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last map[ArrivalKey]*Arrival
+		for {
+			response, err := s.GetContext(ctx, r)
+			if nil != ctx.Err() {
+				return
+			}
+
+			var changes []ArrivalEvent
+			if nil != err {
+				changes = []ArrivalEvent{{Type: ArrivalError, Err: err, At: time.Now()}}
+			}
+			if nil != response {
+				// Partial results are compared, keeping the last known
+				// arrivals at locations which failed.
+				failed := make(map[int]bool)
+				var partial *PartialArrivalsError
+				if errors.As(err, &partial) {
+					for _, id := range partial.FailedLocationIDs() {
+						failed[id] = true
+					}
+				}
+				last, changes = diffArrivals(last, response, failed, changes)
+			}
+
+			for _, e := range changes {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// DiffArrivals reports how the arrivals in current have changed since
+// previous, as Watch would between two polls.  A nil previous reports every
+// arrival in current as appeared, and a nil current every arrival in
+// previous as departed or dropped.
+func DiffArrivals(previous, current *ArrivalsResponse) []ArrivalEvent {
+	if nil == current {
+		current = new(ArrivalsResponse)
+	}
+	last := make(map[ArrivalKey]*Arrival)
+	if nil != previous {
+		for i := range previous.Arrivals {
+			last[NewArrivalKey(&previous.Arrivals[i])] = &previous.Arrivals[i]
+		}
+	}
+	_, events := diffArrivals(last, current, nil, nil)
+	return events
+}
+
+// diffArrivals compares the arrivals in response with those last reported,
+// appending the changes to events.  It returns the arrivals in response by
+// key, along with those last reported at the failed locations.
+func diffArrivals(last map[ArrivalKey]*Arrival, response *ArrivalsResponse, failed map[int]bool, events []ArrivalEvent) (map[ArrivalKey]*Arrival, []ArrivalEvent) {
+	now := queryTime(response)
+	current := make(map[ArrivalKey]*Arrival, len(response.Arrivals))
+	for i := range response.Arrivals {
+		a := &response.Arrivals[i]
+		key := NewArrivalKey(a)
+		current[key] = a
+
+		previous, ok := last[key]
+		if !ok {
+			events = append(events, ArrivalEvent{Type: ArrivalAppeared, Key: key, Arrival: a, At: now})
+			continue
+		}
+		if previous.Status != a.Status {
+			events = append(events, ArrivalEvent{Type: ArrivalStatusChanged, Key: key, Arrival: a, Previous: previous, At: now})
+		}
+		if !sameTime(previous.Estimated, a.Estimated) {
+			events = append(events, ArrivalEvent{Type: ArrivalETAChanged, Key: key, Arrival: a, Previous: previous, At: now})
+		}
+		if !previous.Departed && a.Departed {
+			events = append(events, ArrivalEvent{Type: ArrivalVehicleDeparted, Key: key, Arrival: a, Previous: previous, At: now})
+		}
+	}
+
+	var gone []ArrivalKey
+	for key, a := range last {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		if failed[key.Location] {
+			current[key] = a
+			continue
+		}
+		gone = append(gone, key)
+	}
+	sort.Slice(gone, func(i, j int) bool {
+		return expectedTime(last[gone[i]]).Before(expectedTime(last[gone[j]]))
+	})
+	for _, key := range gone {
+		a := last[key]
+		e := ArrivalEvent{Type: ArrivalDropped, Key: key, Arrival: a, At: now}
+		if expected := expectedTime(a); a.Departed || (!expected.IsZero() && !expected.After(now)) {
+			e.Type = ArrivalDeparted
+		}
+		events = append(events, e)
+	}
+	return current, events
+}
+
+// expectedTime returns the estimated time of a, or its scheduled time if it
+// has not been estimated.
+func expectedTime(a *Arrival) time.Time {
+	for _, t := range []*Time{a.Estimated, a.Scheduled} {
+		if nil != t && nil != t.Time {
+			return *t.Time
+		}
+	}
+	return time.Time{}
+}
+
+// queryTime returns the query time of response, or the current time if it
+// has none.
+func queryTime(response *ArrivalsResponse) time.Time {
+	if nil != response.QueryTime && nil != response.QueryTime.Time {
+		return *response.QueryTime.Time
+	}
+	return time.Now()
+}
+
+func sameTime(a, b *Time) bool {
+	aNil, bNil := nil == a || nil == a.Time, nil == b || nil == b.Time
+	if aNil || bNil {
+		return aNil == bNil
+	}
+	return a.Equal(*b.Time)
+}
@@ -0,0 +1,187 @@
+package trimet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchArrival formats an arrival at stop 8989 for a test response.
+func watchArrival(block int, status, scheduled, estimated string) string {
+	a := fmt.Sprintf(`{"locid":8989,"route":15,"dir":1,"block":%d,"status":%q,"scheduled":"2014-01-12T%s:00.000-0800"`,
+		block, status, scheduled)
+	if "" != estimated {
+		a += fmt.Sprintf(`,"estimated":"2014-01-12T%s:00.000-0800"`, estimated)
+	}
+	return a + "}"
+}
+
+func watchResponse(queryTime string, arrivals ...string) string {
+	body := `{"resultSet":{"queryTime":"2014-01-12T` + queryTime + `:00.000-0800","arrival":[`
+	for i, a := range arrivals {
+		if 0 != i {
+			body += ","
+		}
+		body += a
+	}
+	return body + "]}}"
+}
+
+func TestArrivalsService_Watch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	responses := []string{
+		watchResponse("17:12",
+			watchArrival(1, "scheduled", "17:46", ""),
+			watchArrival(2, "estimated", "17:20", "17:21")),
+		"",
+		watchResponse("17:25",
+			watchArrival(1, "estimated", "17:46", "17:47"),
+			watchArrival(3, "estimated", "17:50", "17:50")),
+		watchResponse("17:26",
+			watchArrival(1, "estimated", "17:46", "17:47")),
+	}
+	var mu sync.Mutex
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body := responses[0]
+		if 1 < len(responses) {
+			responses = responses[1:]
+		}
+		mu.Unlock()
+
+		if "" == body {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(body))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := client.Arrivals.Watch(ctx, &ArrivalsRequest{LocationIDs: []int{8989}}, time.Millisecond)
+
+	expect := []struct {
+		Type  ArrivalEventType
+		Block int
+	}{
+		{ArrivalAppeared, 1},
+		{ArrivalAppeared, 2},
+		{ArrivalError, 0},
+		{ArrivalStatusChanged, 1},
+		{ArrivalETAChanged, 1},
+		{ArrivalAppeared, 3},
+		{ArrivalDeparted, 2},
+		{ArrivalDropped, 3},
+	}
+	var found []struct {
+		Type  ArrivalEventType
+		Block int
+	}
+	for len(found) < len(expect) {
+		select {
+		case e := <-events:
+			if ArrivalError == e.Type && !errors.Is(e.Err, ErrServerUnavailable) {
+				t.Errorf("Expected ErrServerUnavailable, found %v", e.Err)
+			}
+			if ArrivalStatusChanged == e.Type && ("scheduled" != e.Previous.Status || "estimated" != e.Arrival.Status) {
+				t.Errorf("Expected status change from scheduled to estimated, found %+v", e)
+			}
+			found = append(found, struct {
+				Type  ArrivalEventType
+				Block int
+			}{e.Type, e.Key.Block})
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for events, found %v", found)
+		}
+	}
+	if !reflect.DeepEqual(expect, found) {
+		t.Errorf("Expected events %v, found %v", expect, found)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestArrivalEventType_String(t *testing.T) {
+	if "eta changed" != ArrivalETAChanged.String() || "unknown" != ArrivalEventType(0).String() {
+		t.Errorf("Unexpected event type names %v, %v", ArrivalETAChanged, ArrivalEventType(0))
+	}
+}
+
+func TestDiffArrivals(t *testing.T) {
+	decode := func(body string) *ArrivalsResponse {
+		results := new(arrivalsResponseResults)
+		if err := json.Unmarshal([]byte(body), results); nil != err {
+			t.Fatalf("Unable to decode arrivals: %v", err)
+		}
+		return results.Results
+	}
+	previous := decode(watchResponse("17:12",
+		watchArrival(1, "scheduled", "17:46", ""),
+		watchArrival(2, "estimated", "17:20", "17:21")))
+	current := decode(watchResponse("17:25",
+		watchArrival(1, "estimated", "17:46", "17:47"),
+		watchArrival(3, "estimated", "17:50", "17:50")))
+
+	var found []ArrivalEventType
+	for _, e := range DiffArrivals(previous, current) {
+		found = append(found, e.Type)
+	}
+	expect := []ArrivalEventType{ArrivalStatusChanged, ArrivalETAChanged, ArrivalAppeared, ArrivalDeparted}
+	if !reflect.DeepEqual(expect, found) {
+		t.Errorf("Expected events %v, found %v", expect, found)
+	}
+
+	if events := DiffArrivals(nil, current); 2 != len(events) || ArrivalAppeared != events[0].Type {
+		t.Errorf("Expected every arrival to appear, found %+v", events)
+	}
+}
+
+func TestDiffArrivals_departed(t *testing.T) {
+	previous := &ArrivalsResponse{Arrivals: []Arrival{
+		{Location: 8989, Route: 15, Block: 1, Departed: true,
+			Scheduled: NewTime(time.Now().Add(time.Hour))},
+		{Location: 8989, Route: 15, Block: 2,
+			Scheduled: NewTime(time.Now().Add(2 * time.Hour))},
+	}}
+
+	var found []ArrivalEventType
+	for _, e := range DiffArrivals(previous, nil) {
+		found = append(found, e.Type)
+	}
+	expect := []ArrivalEventType{ArrivalDeparted, ArrivalDropped}
+	if !reflect.DeepEqual(expect, found) {
+		t.Errorf("Expected events %v, found %v", expect, found)
+	}
+}
+
+func TestDiffArrivals_vehicleDeparted(t *testing.T) {
+	scheduled := NewTime(time.Now().Add(time.Hour))
+	previous := &ArrivalsResponse{Arrivals: []Arrival{
+		{Location: 8989, Route: 15, Block: 1, Scheduled: scheduled},
+	}}
+	current := &ArrivalsResponse{Arrivals: []Arrival{
+		{Location: 8989, Route: 15, Block: 1, Scheduled: scheduled, Departed: true},
+	}}
+
+	events := DiffArrivals(previous, current)
+	if 1 != len(events) || ArrivalVehicleDeparted != events[0].Type || !events[0].Arrival.Departed {
+		t.Errorf("Expected a vehicle departed event, found %+v", events)
+	}
+	if "vehicle departed" != events[0].Type.String() {
+		t.Errorf("Expected type \"vehicle departed\", found %q", events[0].Type)
+	}
+
+	if events := DiffArrivals(current, current); 0 != len(events) {
+		t.Errorf("Expected no events once departed, found %+v", events)
+	}
+}
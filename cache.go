@@ -0,0 +1,107 @@
+package trimet
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// A Cache stores raw API responses so that repeated requests can be served
+// without contacting TriMet.
+//
+// Implementations must be safe for concurrent use.  Caching is best effort:
+// implementations may drop entries at any time.
+type Cache interface {
+	// Get returns the entry stored for key, if any.
+	Get(key string) (*CacheEntry, bool)
+
+	// Set stores entry for key, replacing any existing entry.
+	Set(key string, entry *CacheEntry)
+}
+
+// A CacheEntry is a cached API response.
+type CacheEntry struct {
+	// Raw response body.
+	Body []byte `json:"body"`
+
+	// Time after which the entry is stale.
+	Expires time.Time `json:"expires"`
+}
+
+// Fresh reports whether the entry may still be used at time now.
+func (e *CacheEntry) Fresh(now time.Time) bool {
+	return nil != e && now.Before(e.Expires)
+}
+
+// DefaultCacheTTLs are the time to live of cached responses for each
+// endpoint when the Client does not specify its own.
+//
+// Route and stop configuration changes only with the schedule, while
+// arrivals are updated continuously.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"arrivals":    10 * time.Second,
+	"detours":     time.Minute,
+	"routeConfig": 24 * time.Hour,
+	"stops":       24 * time.Hour,
+	"v2/alerts":   time.Minute,
+	"v2/arrivals": 10 * time.Second,
+	"v2/vehicles": 5 * time.Second,
+}
+
+// newCacheEntry creates a CacheEntry for the response body data which lives
+// for ttl.
+//
+// Freshness is measured from the queryTime reported in the response when it
+// is present and not in the future, so that a response is not considered
+// fresher than TriMet's data.
+func newCacheEntry(data []byte, ttl time.Duration, now time.Time) *CacheEntry {
+	base := now
+	if queryTime := responseQueryTime(data); nil != queryTime && queryTime.Before(now) {
+		base = *queryTime
+	}
+
+	return &CacheEntry{
+		Body:    data,
+		Expires: base.Add(ttl),
+	}
+}
+
+// responseQueryTime returns the queryTime of the result set in data, if any.
+func responseQueryTime(data []byte) *time.Time {
+	results := new(struct {
+		Results *Response `json:"resultSet"`
+	})
+	if err := json.Unmarshal(data, results); nil != err {
+		return nil
+	}
+	if nil == results.Results || nil == results.Results.QueryTime {
+		return nil
+	}
+	return results.Results.QueryTime.Time
+}
+
+// cacheKey returns the key under which the response to req is cached, along
+// with its time to live.  A zero TTL indicates the response is not cacheable.
+//
+// The key is the request URL without the appID, so that clients using
+// different AppIDs may share a Cache.
+func (c *Client) cacheKey(req *http.Request) (string, time.Duration) {
+	if nil == c.Cache || nil == req.URL {
+		return "", 0
+	}
+
+	ttls := c.CacheTTLs
+	if nil == ttls {
+		ttls = DefaultCacheTTLs
+	}
+	ttl := ttls[c.endpoint(req.URL.Path)]
+	if ttl <= 0 {
+		return "", 0
+	}
+
+	u := *req.URL
+	q := u.Query()
+	q.Del("appID")
+	u.RawQuery = q.Encode()
+	return u.String(), ttl
+}
@@ -0,0 +1,176 @@
+package trimet
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGet_cached(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/routeConfig", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		b, err := ioutil.ReadFile("testdata/routeConfig.json")
+		if nil != err {
+			t.Fatal("Unable to read testdata/routeConfig.json")
+		}
+		w.Write(b)
+	})
+
+	client.Cache = NewMemoryCache(10)
+
+	req := &RouteConfigRequest{Routes: []int{193}}
+	first, err := client.Routes.Get(req)
+	if nil != err {
+		t.Fatalf("Routes.Get returned error: %v", err)
+	}
+	second, err := client.Routes.Get(req)
+	if nil != err {
+		t.Fatalf("Routes.Get returned error: %v", err)
+	}
+
+	if 1 != requests {
+		t.Errorf("Expected 1 request to be sent, found %v", requests)
+	}
+	if len(first.Routes) != len(second.Routes) || 0 == len(second.Routes) {
+		t.Errorf("Expected cached response %+v to match %+v", second, first)
+	}
+}
+
+func TestGet_cacheUncachedEndpoint(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"resultSet":{}}`))
+	})
+
+	client.Cache = NewMemoryCache(10)
+	client.CacheTTLs = map[string]time.Duration{"routeConfig": time.Hour}
+
+	req := &ArrivalsRequest{LocationIDs: []int{8989}}
+	client.Arrivals.Get(req)
+	client.Arrivals.Get(req)
+
+	if 2 != requests {
+		t.Errorf("Expected 2 requests to be sent, found %v", requests)
+	}
+}
+
+func TestGet_cacheStale(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/stops", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"resultSet":{}}`))
+	})
+
+	cache := NewMemoryCache(10)
+	client.Cache = cache
+
+	req := &StopsRequest{LonLat: []float64{-122.68, 45.53}, Feet: 500}
+	client.Stops.Get(req)
+
+	httpReq, _ := client.NewRequest("GET", "stops", req)
+	key, _ := client.cacheKey(httpReq)
+	entry, ok := cache.Get(key)
+	if !ok {
+		t.Fatalf("Expected response to be cached under %v", key)
+	}
+	entry.Expires = time.Now().Add(-time.Second)
+
+	client.Stops.Get(req)
+	if 2 != requests {
+		t.Errorf("Expected stale entry to be refreshed, found %v requests", requests)
+	}
+}
+
+func TestClient_cacheKey(t *testing.T) {
+	c := NewClient(testAppID, nil)
+	c.Cache = NewMemoryCache(1)
+
+	req, _ := c.NewRequest("GET", "routeConfig", &RouteConfigRequest{Routes: []int{193}})
+	key, ttl := c.cacheKey(req)
+
+	if strings.Contains(key, testAppID) {
+		t.Errorf("Expected cache key %v to omit the appID", key)
+	}
+	if DefaultCacheTTLs["routeConfig"] != ttl {
+		t.Errorf("Expected TTL = %v, found %v", DefaultCacheTTLs["routeConfig"], ttl)
+	}
+
+	other := NewClient("xyz789", nil)
+	other.Cache = c.Cache
+	otherReq, _ := other.NewRequest("GET", "routeConfig", &RouteConfigRequest{Routes: []int{193}})
+	if otherKey, _ := other.cacheKey(otherReq); key != otherKey {
+		t.Errorf("Expected cache keys to match across AppIDs: %v != %v", key, otherKey)
+	}
+}
+
+func TestNewCacheEntry_queryTime(t *testing.T) {
+	now := time.Date(2014, 1, 12, 17, 12, 19, 0, time.UTC)
+	data := []byte(`{"resultSet":{"queryTime":"2014-01-12T09:12:09.000-0800"}}`)
+
+	entry := newCacheEntry(data, 30*time.Second, now)
+
+	expect := time.Date(2014, 1, 12, 17, 12, 39, 0, time.UTC)
+	if !expect.Equal(entry.Expires) {
+		t.Errorf("Expected entry to expire at %v, found %v", expect, entry.Expires)
+	}
+}
+
+func TestMemoryCache_evict(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", &CacheEntry{})
+	c.Set("b", &CacheEntry{})
+	c.Get("a")
+	c.Set("c", &CacheEntry{})
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Expected recently used entry to be retained")
+	}
+	if 2 != c.Len() {
+		t.Errorf("Expected 2 entries, found %v", c.Len())
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotrimet")
+	if nil != err {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := NewDiskCache(dir)
+	if nil != err {
+		t.Fatalf("Unexpected error creating DiskCache: %v", err)
+	}
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Expected miss for missing key")
+	}
+
+	expires := time.Date(2014, 1, 12, 17, 12, 9, 0, time.UTC)
+	c.Set("key", &CacheEntry{Body: []byte(`{}`), Expires: expires})
+
+	entry, ok := c.Get("key")
+	if !ok {
+		t.Fatal("Expected hit for stored key")
+	}
+	if `{}` != string(entry.Body) || !expires.Equal(entry.Expires) {
+		t.Errorf("Expected stored entry, found %+v", entry)
+	}
+}
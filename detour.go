@@ -4,26 +4,26 @@ package trimet
 // routes at the time the query was made.
 type Detour struct {
 	// A unique identifier of the detour.
-	ID string `json:"id"`
+	ID string `json:"id" xml:"id,attr"`
 
 	// Time the detour begins. This will always be a time in the past.
 	// This field is used internally and may be of little use
 	// outside of TriMet.
-	Begin *Time `json:"begin"`
+	Begin *Time `json:"begin" xml:"begin,attr"`
 
 	// The time the detour will become invalid. Note that this will always be a
 	// time in the future. Some end times will be very far in the future and
 	// will be removed once the detour is no longer in effect. This field is
 	// used internally and may be of little use outside of TriMet.
-	End *Time `json:"end"`
+	End *Time `json:"end" xml:"end,attr"`
 
 	// A plain text description of the detour.
-	Description string `json:"desc"`
+	Description string `json:"desc" xml:"desc,attr"`
 
 	// A phonetic spelling of the route detour. This field is used by TriMet's
 	// 238-Ride text-to-speech system.
-	Phonetic string `json:"phonetic"`
+	Phonetic string `json:"phonetic" xml:"phonetic,attr"`
 
 	// Occurs for every route the detour is applicable.
-	Routes []Route `json:"route"`
+	Routes []Route `json:"route" xml:"route"`
 }
@@ -0,0 +1,70 @@
+package trimet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// A DetourStore persists the detours last seen by DetoursService.Watch, so
+// that a restarted watch reports only the changes made since.
+type DetourStore interface {
+	// Load returns the detours last saved, or none if nothing was saved.
+	Load() ([]Detour, error)
+
+	// Save replaces the saved detours.
+	Save(detours []Detour) error
+}
+
+// DetourFile is a DetourStore which saves detours as JSON in a file.
+type DetourFile struct {
+	path string
+}
+
+// NewDetourFile returns a DetourFile saving detours to path.
+func NewDetourFile(path string) *DetourFile {
+	return &DetourFile{path: path}
+}
+
+// Load implements DetourStore.  A missing file holds no detours.
+func (f *DetourFile) Load() ([]Detour, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	var detours []Detour
+	if err := json.Unmarshal(data, &detours); nil != err {
+		return nil, err
+	}
+	return detours, nil
+}
+
+// Save implements DetourStore.
+func (f *DetourFile) Save(detours []Detour) error {
+	data, err := json.Marshal(detours)
+	if nil != err {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file.
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), ".tmp-")
+	if nil != err {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); nil == err {
+		err = closeErr
+	}
+	if nil == err {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+	if nil != err {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -1,5 +1,11 @@
 package trimet
 
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
 // DetoursService retrieves a list of detours currently in effect by route.
 //
 // TriMet API docs: http://developer.trimet.org/ws_docs/detours_ws.shtml
@@ -17,17 +23,39 @@ type DetoursRequest struct {
 
 type DetoursResponse struct {
 	Response
-	Detours []Detour `json:"detour"`
+	Detours []Detour `json:"detour" xml:"detour"`
 }
 
 type detoursResponseResults struct {
 	Results *DetoursResponse `json:"resultSet,omitempty"`
 }
 
+// UnmarshalXML decodes the XML result set, which is the document root.
+func (r *detoursResponseResults) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	r.Results = new(DetoursResponse)
+	return d.DecodeElement(r.Results, &start)
+}
+
+// Validate checks the request's route numbers.
+func (r *DetoursRequest) Validate() error {
+	v := new(ValidationError)
+	for _, route := range r.Routes {
+		if route < 0 {
+			v.add("Routes", fmt.Sprintf("invalid route number %d", route))
+		}
+	}
+	return v.err()
+}
+
 // Get latest detour information.
 func (s *DetoursService) Get(r *DetoursRequest) (*DetoursResponse, error) {
+	return s.GetContext(context.Background(), r)
+}
+
+// GetContext gets latest detour information, aborting if ctx is done.
+func (s *DetoursService) GetContext(ctx context.Context, r *DetoursRequest) (*DetoursResponse, error) {
 	response := new(detoursResponseResults)
-	err := s.client.Get("detours", r, response)
+	err := s.client.GetContext(ctx, "detours", r, response)
 	if nil != err {
 		return nil, err
 	}
@@ -0,0 +1,195 @@
+package trimet
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// A DetourEventType describes how a detour changed between polls.
+type DetourEventType int
+
+const (
+	// DetourAdded reports a detour not seen before.
+	DetourAdded DetourEventType = iota + 1
+
+	// DetourUpdated reports a change in the description or routes of a
+	// detour.
+	DetourUpdated
+
+	// DetourRemoved reports a detour which is no longer in effect.
+	DetourRemoved
+
+	// DetourError reports a failed poll, or a failure to load or save the
+	// detours seen.  Watching continues.
+	DetourError
+)
+
+var detourEventTypes = map[DetourEventType]string{
+	DetourAdded:   "added",
+	DetourUpdated: "updated",
+	DetourRemoved: "removed",
+	DetourError:   "error",
+}
+
+func (t DetourEventType) String() string {
+	if s, ok := detourEventTypes[t]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// A DetourEvent is a change observed by DetoursService.Watch.
+type DetourEvent struct {
+	Type DetourEventType
+
+	// The detour as currently reported, or as last reported for removed
+	// detours.  Nil for errors.
+	Detour *Detour
+
+	// The detour as previously reported, for updates.
+	Previous *Detour
+
+	// The error of a failed poll, load or save.
+	Err error
+
+	// The query time of the poll which observed the event.
+	At time.Time
+}
+
+// Watch polls for detours every interval, or DefaultWatchInterval if
+// interval is not positive, tracks them by ID and reports how they change on
+// the returned channel.
+//
+// If store is not nil, the detours it holds are compared with the first
+// poll, and the detours seen are saved after every poll which changes them,
+// so that restarting a watch does not report every detour as added again.
+// Without a store every detour is reported as added by the first poll.
+//
+// Failures are reported as DetourError events rather than stopping the
+// watch.  The channel is closed once ctx is done.
+func (s *DetoursService) Watch(ctx context.Context, r *DetoursRequest, interval time.Duration, store DetourStore) <-chan DetourEvent {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	events := make(chan DetourEvent)
+	go func() {
+		defer close(events)
+
+		send := func(changes []DetourEvent) bool {
+			for _, e := range changes {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		var last map[string]*Detour
+		if nil != store {
+			saved, err := store.Load()
+			if nil != err && !send([]DetourEvent{{Type: DetourError, Err: err, At: time.Now()}}) {
+				return
+			}
+			last = detoursByID(saved)
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			response, err := s.GetContext(ctx, r)
+			if nil != ctx.Err() {
+				return
+			}
+
+			var changes []DetourEvent
+			if nil != err {
+				changes = []DetourEvent{{Type: DetourError, Err: err, At: time.Now()}}
+			} else {
+				at := time.Now()
+				if nil != response.QueryTime && nil != response.QueryTime.Time {
+					at = *response.QueryTime.Time
+				}
+				changes = diffDetours(last, response.Detours, at)
+				last = detoursByID(response.Detours)
+
+				if nil != store && 0 != len(changes) {
+					if err := store.Save(response.Detours); nil != err {
+						changes = append(changes, DetourEvent{Type: DetourError, Err: err, At: at})
+					}
+				}
+			}
+
+			if !send(changes) {
+				return
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+func detoursByID(detours []Detour) map[string]*Detour {
+	byID := make(map[string]*Detour, len(detours))
+	for i := range detours {
+		byID[detours[i].ID] = &detours[i]
+	}
+	return byID
+}
+
+// diffDetours compares detours with those last reported.
+func diffDetours(last map[string]*Detour, detours []Detour, at time.Time) []DetourEvent {
+	var events []DetourEvent
+	seen := make(map[string]bool, len(detours))
+	for i := range detours {
+		d := &detours[i]
+		seen[d.ID] = true
+
+		previous, ok := last[d.ID]
+		switch {
+		case !ok:
+			events = append(events, DetourEvent{Type: DetourAdded, Detour: d, At: at})
+		case previous.Description != d.Description || !sameRoutes(previous.Routes, d.Routes):
+			events = append(events, DetourEvent{Type: DetourUpdated, Detour: d, Previous: previous, At: at})
+		}
+	}
+
+	var removed []string
+	for id := range last {
+		if !seen[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		events = append(events, DetourEvent{Type: DetourRemoved, Detour: last[id], At: at})
+	}
+	return events
+}
+
+// sameRoutes reports whether a and b contain the same route numbers,
+// regardless of order.
+func sameRoutes(a, b []Route) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := make(map[int]int, len(a))
+	for _, r := range a {
+		ids[r.ID]++
+	}
+	for _, r := range b {
+		if ids[r.ID]--; ids[r.ID] < 0 {
+			return false
+		}
+	}
+	return true
+}
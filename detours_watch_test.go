@@ -0,0 +1,172 @@
+package trimet
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchDetour formats a detour for a test response.
+func watchDetour(id, desc string, routes ...int) string {
+	d := fmt.Sprintf(`{"id":%q,"desc":%q,"begin":"2014-01-12T08:00:00.000-0800","end":"2037-11-09T02:00:00.000-0800","route":[`, id, desc)
+	for i, r := range routes {
+		if 0 != i {
+			d += ","
+		}
+		d += fmt.Sprintf(`{"route":%d}`, r)
+	}
+	return d + "]}"
+}
+
+func watchDetours(detours ...string) string {
+	body := `{"resultSet":{"queryTime":"2014-01-12T17:12:09.351-0800","detour":[`
+	for i, d := range detours {
+		if 0 != i {
+			body += ","
+		}
+		body += d
+	}
+	return body + "]}}"
+}
+
+// serveDetourSequence serves each of responses in turn, repeating the last.
+// Empty responses fail.
+func serveDetourSequence(responses ...string) {
+	var mu sync.Mutex
+	mux.HandleFunc("/detours", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body := responses[0]
+		if 1 < len(responses) {
+			responses = responses[1:]
+		}
+		mu.Unlock()
+
+		if "" == body {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(body))
+	})
+}
+
+type detourChange struct {
+	Type DetourEventType
+	ID   string
+}
+
+// collectDetourEvents reads n events from events.
+func collectDetourEvents(t *testing.T, events <-chan DetourEvent, n int) []detourChange {
+	var found []detourChange
+	for len(found) < n {
+		select {
+		case e := <-events:
+			id := ""
+			if nil != e.Detour {
+				id = e.Detour.ID
+			}
+			found = append(found, detourChange{e.Type, id})
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for events, found %v", found)
+		}
+	}
+	return found
+}
+
+func TestDetoursService_Watch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	serveDetourSequence(
+		watchDetours(watchDetour("1", "a", 12), watchDetour("2", "b", 15)),
+		"",
+		watchDetours(watchDetour("1", "a", 12, 94), watchDetour("3", "c", 4)),
+		watchDetours(watchDetour("1", "a", 94, 12), watchDetour("3", "c", 4)),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := client.Detours.Watch(ctx, &DetoursRequest{}, time.Millisecond, nil)
+
+	expect := []detourChange{
+		{DetourAdded, "1"},
+		{DetourAdded, "2"},
+		{DetourError, ""},
+		{DetourUpdated, "1"},
+		{DetourAdded, "3"},
+		{DetourRemoved, "2"},
+	}
+	if found := collectDetourEvents(t, events, len(expect)); !reflect.DeepEqual(expect, found) {
+		t.Errorf("Expected events %v, found %v", expect, found)
+	}
+
+	// Reordering routes is not an update.
+	select {
+	case e := <-events:
+		t.Errorf("Unexpected event %v for %+v", e.Type, e.Detour)
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestDetoursService_Watch_store(t *testing.T) {
+	setup()
+	defer teardown()
+
+	dir, err := ioutil.TempDir("", "gotrimet")
+	if nil != err {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	store := NewDetourFile(filepath.Join(dir, "detours.json"))
+
+	serveDetourSequence(
+		watchDetours(watchDetour("1", "a", 12)),
+		watchDetours(watchDetour("1", "a", 12), watchDetour("2", "b", 15)),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := client.Detours.Watch(ctx, &DetoursRequest{}, time.Hour, store)
+	found := collectDetourEvents(t, events, 1)
+	cancel()
+	for range events {
+	}
+	if expect := []detourChange{{DetourAdded, "1"}}; !reflect.DeepEqual(expect, found) {
+		t.Errorf("Expected events %v, found %v", expect, found)
+	}
+
+	saved, err := store.Load()
+	if nil != err || 1 != len(saved) || "1" != saved[0].ID || nil == saved[0].End {
+		t.Fatalf("Expected detour 1 to be saved, found %+v, %v", saved, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events = client.Detours.Watch(ctx, &DetoursRequest{}, time.Hour, store)
+	if expect, found := []detourChange{{DetourAdded, "2"}}, collectDetourEvents(t, events, 1); !reflect.DeepEqual(expect, found) {
+		t.Errorf("Expected only new detours after restart, found %v", found)
+	}
+}
+
+func TestDetourFile_Load_corrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotrimet")
+	if nil != err {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "detours.json")
+	ioutil.WriteFile(path, []byte("{"), 0644)
+
+	if _, err := NewDetourFile(path).Load(); nil == err {
+		t.Errorf("Expected error loading corrupt file, found %v", err)
+	}
+}
@@ -2,11 +2,11 @@ package trimet
 
 type Direction struct {
 	// The number of the direction, either 1 for inbound or 0 for outbound.
-	Number int `json:"dir"`
+	Number int `json:"dir" xml:"dir,attr"`
 
 	// Describes the direction of the route.
-	Description string `json:"desc"`
+	Description string `json:"desc" xml:"desc,attr"`
 
 	// List of stops included in the direction of a route.
-	Locations []Location `json:"stop"`
+	Locations []Location `json:"stop" xml:"stop"`
 }
@@ -0,0 +1,72 @@
+package trimet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// DiskCache is a Cache which stores each entry as a file in a directory, so
+// that cached responses survive restarts.
+//
+// Errors reading or writing entries are treated as cache misses.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache storing entries in dir, which is created
+// if it does not exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); nil != err {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get implements Cache.
+func (c *DiskCache) Get(key string) (*CacheEntry, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if nil != err {
+		return nil, false
+	}
+
+	entry := new(CacheEntry)
+	if err := json.Unmarshal(data, entry); nil != err {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Set implements Cache.
+func (c *DiskCache) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if nil != err {
+		return
+	}
+
+	// Write to a temporary file first so readers never see partial entries.
+	tmp, err := ioutil.TempFile(c.dir, ".tmp-")
+	if nil != err {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); nil == err {
+		err = closeErr
+	}
+	if nil != err {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); nil != err {
+		os.Remove(tmp.Name())
+	}
+}
+
+// path returns the file in which the entry for key is stored.
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,137 @@
+package trimet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Classes of errors reported by the TriMet API.
+//
+// Errors returned from Client.Do and the services wrap one of these when the
+// cause can be determined, so they may be tested with errors.Is:
+//
+//	if errors.Is(err, trimet.ErrServerUnavailable) {
+//	    // try again later
+//	}
+var (
+	// The AppID was rejected by TriMet.
+	ErrInvalidAppID = errors.New("Invalid AppID")
+
+	// A requested location ID does not identify a known stop.
+	ErrUnknownLocation = errors.New("Unknown location ID")
+
+	// More location IDs were requested than the service allows.
+	ErrTooManyLocations = errors.New("Too many location IDs")
+
+	// The request parameters could not be understood by the service.
+	ErrMalformedParameters = errors.New("Malformed request parameters")
+
+	// The service is down or failed to handle the request.
+	ErrServerUnavailable = errors.New("TriMet server unavailable")
+
+	// The response body could not be decoded as JSON.
+	ErrNonJSONResponse = errors.New("Response body is not JSON")
+
+	// The response body could not be decoded as XML.
+	ErrNonXMLResponse = errors.New("Response body is not XML")
+)
+
+// A StatusError reports an HTTP error status returned without a TriMet error
+// message.
+type StatusError struct {
+	// HTTP response that caused this error.
+	Response *http.Response
+
+	// Raw response body, if any.
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%v %v: %d %s",
+		e.Response.Request.Method, e.Response.Request.URL,
+		e.Response.StatusCode, http.StatusText(e.Response.StatusCode))
+}
+
+// Unwrap returns the class of error indicated by the HTTP status, or nil if
+// the status does not indicate one.
+func (e *StatusError) Unwrap() error {
+	return classifyStatus(e.Response.StatusCode)
+}
+
+// A DecodeError reports a response body that could not be decoded.
+//
+// DecodeError matches ErrNonJSONResponse or ErrNonXMLResponse, depending on
+// the format requested, when tested with errors.Is.
+type DecodeError struct {
+	// The error returned by the decoder.
+	Err error
+
+	// Raw response body.
+	Body []byte
+
+	// Whether the body was expected to be XML rather than JSON.
+	XML bool
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%v: %v", e.kind(), e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the class of error for the expected format.
+func (e *DecodeError) Is(target error) bool {
+	return e.kind() == target
+}
+
+func (e *DecodeError) kind() error {
+	if e.XML {
+		return ErrNonXMLResponse
+	}
+	return ErrNonJSONResponse
+}
+
+// classifyStatus maps an HTTP status code to a class of error.
+func classifyStatus(code int) error {
+	switch {
+	case code >= 500:
+		return ErrServerUnavailable
+	case http.StatusUnauthorized == code, http.StatusForbidden == code:
+		return ErrInvalidAppID
+	case http.StatusBadRequest == code:
+		return ErrMalformedParameters
+	}
+	return nil
+}
+
+// classifyMessage maps the text of a TriMet errorMessage to a class of error.
+//
+// TriMet reports most request errors with a 200 status and a free-form
+// message, so the text is the only indication of what went wrong.
+func classifyMessage(message string) error {
+	m := strings.ToLower(message)
+	switch {
+	case strings.Contains(m, "appid"):
+		return ErrInvalidAppID
+	case strings.Contains(m, "too many"), strings.Contains(m, "maximum"):
+		return ErrTooManyLocations
+	case strings.Contains(m, "location") &&
+		(strings.Contains(m, "not found") ||
+			strings.Contains(m, "unknown") ||
+			strings.Contains(m, "invalid")):
+		return ErrUnknownLocation
+	case strings.Contains(m, "invalid"),
+		strings.Contains(m, "missing"),
+		strings.Contains(m, "required"),
+		strings.Contains(m, "must"),
+		strings.Contains(m, "malformed"):
+		return ErrMalformedParameters
+	case strings.Contains(m, "unavailable"), strings.Contains(m, "try again"):
+		return ErrServerUnavailable
+	}
+	return nil
+}
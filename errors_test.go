@@ -0,0 +1,160 @@
+package trimet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		expect  error
+	}{
+		{"Invalid appID", ErrInvalidAppID},
+		{"AppID is not registered", ErrInvalidAppID},
+		{"Location id not found 99999", ErrUnknownLocation},
+		{"Invalid location id: abc", ErrUnknownLocation},
+		{"Too many location ids requested", ErrTooManyLocations},
+		{"Maximum of 10 location ids allowed", ErrTooManyLocations},
+		{"Invalid bbox argument", ErrMalformedParameters},
+		{"Missing required argument ll", ErrMalformedParameters},
+		{"Service temporarily unavailable", ErrServerUnavailable},
+		{"Something else entirely", nil},
+	}
+
+	for _, test := range tests {
+		if err := classifyMessage(test.message); test.expect != err {
+			t.Errorf("Expected classifyMessage(%q) = %v, found %v", test.message, test.expect, err)
+		}
+	}
+}
+
+func TestClassifyStatus(t *testing.T) {
+	tests := []struct {
+		code   int
+		expect error
+	}{
+		{http.StatusOK, nil},
+		{http.StatusBadRequest, ErrMalformedParameters},
+		{http.StatusUnauthorized, ErrInvalidAppID},
+		{http.StatusForbidden, ErrInvalidAppID},
+		{http.StatusNotFound, nil},
+		{http.StatusInternalServerError, ErrServerUnavailable},
+		{http.StatusServiceUnavailable, ErrServerUnavailable},
+	}
+
+	for _, test := range tests {
+		if err := classifyStatus(test.code); test.expect != err {
+			t.Errorf("Expected classifyStatus(%v) = %v, found %v", test.code, test.expect, err)
+		}
+	}
+}
+
+func TestErrorResponse_Is(t *testing.T) {
+	res := &http.Response{Request: &http.Request{}, StatusCode: http.StatusOK}
+	err := error(&ErrorResponse{http: res})
+	err.(*ErrorResponse).Message.Content = "Location id not found 1"
+
+	if !errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("Expected %v to match ErrUnknownLocation", err)
+	}
+	if errors.Is(err, ErrInvalidAppID) {
+		t.Errorf("Expected %v not to match ErrInvalidAppID", err)
+	}
+
+	var errorResponse *ErrorResponse
+	if !errors.As(err, &errorResponse) {
+		t.Errorf("Expected %v to be an *ErrorResponse", err)
+	}
+}
+
+func TestErrorResponse_Is_statusFallback(t *testing.T) {
+	res := &http.Response{Request: &http.Request{}, StatusCode: http.StatusBadGateway}
+	err := &ErrorResponse{http: res}
+	err.Message.Content = "Something else entirely"
+
+	if !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("Expected %v to match ErrServerUnavailable", err)
+	}
+}
+
+func TestDo_statusError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.Do(req, nil)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected *StatusError, found %#v", err)
+	}
+	if http.StatusServiceUnavailable != statusErr.Response.StatusCode {
+		t.Errorf("Expected status %v, found %v", http.StatusServiceUnavailable, statusErr.Response.StatusCode)
+	}
+	if !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("Expected %v to match ErrServerUnavailable", err)
+	}
+}
+
+func TestDo_trimetErrorClassified(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errorMessage":{"content":"Invalid appID"}}`)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.Do(req, nil)
+
+	if !errors.Is(err, ErrInvalidAppID) {
+		t.Errorf("Expected %v to match ErrInvalidAppID", err)
+	}
+}
+
+func TestDo_trimetErrorInResultSet(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultSet":{"errorMessage":{"content":"Location id not found 1"}}}`)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.Do(req, new(arrivalsResponseResults))
+
+	if !errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("Expected %v to match ErrUnknownLocation", err)
+	}
+}
+
+func TestDo_nonJSON(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body>Maintenance</body></html>`)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.Do(req, new(struct{}))
+
+	if !errors.Is(err, ErrNonJSONResponse) {
+		t.Errorf("Expected %v to match ErrNonJSONResponse", err)
+	}
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Expected *DecodeError, found %#v", err)
+	}
+	if 0 == len(decodeErr.Body) {
+		t.Error("Expected DecodeError to retain the response body")
+	}
+}
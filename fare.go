@@ -0,0 +1,14 @@
+package trimet
+
+// A Fare contains the cost in dollars of an itinerary for each class of
+// rider.
+type Fare struct {
+	// Adult fare.
+	Regular float64 `xml:"regular"`
+
+	// Fare for seniors, riders with disabilities and Medicare recipients.
+	Honored float64 `xml:"honored"`
+
+	// Fare for riders aged 7 to 17.
+	Youth float64 `xml:"youth"`
+}
@@ -0,0 +1,9 @@
+module github.com/juniorrobot/gotrimet
+
+go 1.23
+
+require (
+	github.com/google/go-querystring v1.1.0
+	github.com/gorilla/websocket v1.5.3
+	google.golang.org/protobuf v1.36.10
+)
@@ -0,0 +1,22 @@
+package gtfs
+
+// An Agency is a transit agency whose services are described by the feed.
+type Agency struct {
+	ID       string
+	Name     string
+	URL      string
+	Timezone string
+	Phone    string
+}
+
+func readAgencies(t *table, f *Feed) {
+	for t.next() {
+		f.Agencies = append(f.Agencies, &Agency{
+			ID:       t.str("agency_id"),
+			Name:     t.str("agency_name"),
+			URL:      t.str("agency_url"),
+			Timezone: t.str("agency_timezone"),
+			Phone:    t.str("agency_phone"),
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package gtfs
+
+import "time"
+
+// A Calendar is the weekly schedule on which a service operates between two
+// dates.
+type Calendar struct {
+	ServiceID string
+	Weekdays  [7]bool // Indexed by time.Weekday.
+	Start     time.Time
+	End       time.Time
+}
+
+// Includes reports whether the calendar's weekly schedule includes date.
+func (c *Calendar) Includes(date time.Time) bool {
+	d := day(date)
+	return c.Weekdays[d.Weekday()] && !d.Before(c.Start) && !d.After(c.End)
+}
+
+func readCalendars(t *table, f *Feed) {
+	days := [7]string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	for t.next() {
+		c := &Calendar{
+			ServiceID: t.str("service_id"),
+			Start:     t.date("start_date"),
+			End:       t.date("end_date"),
+		}
+		for i, name := range days {
+			c.Weekdays[i] = t.bool(name)
+		}
+		f.Calendars[c.ServiceID] = c
+	}
+}
+
+// day returns the calendar date of t as midnight UTC, as GTFS dates are
+// parsed.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
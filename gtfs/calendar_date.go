@@ -0,0 +1,27 @@
+package gtfs
+
+import "time"
+
+// Calendar date exception types.
+const (
+	ServiceAdded   = 1
+	ServiceRemoved = 2
+)
+
+// A CalendarDate adds or removes a service on a single date.
+type CalendarDate struct {
+	ServiceID     string
+	Date          time.Time
+	ExceptionType int
+}
+
+func readCalendarDates(t *table, f *Feed) {
+	for t.next() {
+		cd := &CalendarDate{
+			ServiceID:     t.str("service_id"),
+			Date:          t.date("date"),
+			ExceptionType: t.int("exception_type"),
+		}
+		f.CalendarDates[cd.ServiceID] = append(f.CalendarDates[cd.ServiceID], cd)
+	}
+}
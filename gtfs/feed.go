@@ -0,0 +1,207 @@
+// Package gtfs reads TriMet's static GTFS feed, which provides the
+// timetables, calendars and shapes not exposed by the web services.
+//
+// TriMet's GTFS stop and route IDs are the location IDs and route numbers
+// used by the web services, so results from a trimet.Client may be joined to
+// the feed with StopByLocationID and RouteByID.
+//
+// TriMet GTFS feed: http://developer.trimet.org/schedule/gtfs.zip
+package gtfs
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// A Feed is a parsed GTFS feed, indexed by ID.
+type Feed struct {
+	Agencies      []*Agency
+	Stops         map[string]*Stop
+	Routes        map[string]*Route
+	Trips         map[string]*Trip
+	Calendars     map[string]*Calendar
+	CalendarDates map[string][]*CalendarDate
+
+	// Shape points keyed by shape ID, ordered by sequence.
+	Shapes map[string][]ShapePoint
+
+	stopTimes []*StopTime
+}
+
+// feedFiles are the files read from a feed, in the order they are read.
+// Files missing from a feed are skipped.
+var feedFiles = []struct {
+	name string
+	read func(*table, *Feed)
+}{
+	{"agency.txt", readAgencies},
+	{"stops.txt", readStops},
+	{"routes.txt", readRoutes},
+	{"trips.txt", readTrips},
+	{"stop_times.txt", readStopTimes},
+	{"calendar.txt", readCalendars},
+	{"calendar_dates.txt", readCalendarDates},
+	{"shapes.txt", readShapes},
+}
+
+// Open reads the GTFS zip file at path.
+func Open(path string) (*Feed, error) {
+	z, err := zip.OpenReader(path)
+	if nil != err {
+		return nil, err
+	}
+	defer z.Close()
+	return read(&z.Reader)
+}
+
+// Read reads a GTFS zip archive of size bytes from r.
+func Read(r io.ReaderAt, size int64) (*Feed, error) {
+	z, err := zip.NewReader(r, size)
+	if nil != err {
+		return nil, err
+	}
+	return read(z)
+}
+
+func read(z *zip.Reader) (*Feed, error) {
+	f := &Feed{
+		Stops:         make(map[string]*Stop),
+		Routes:        make(map[string]*Route),
+		Trips:         make(map[string]*Trip),
+		Calendars:     make(map[string]*Calendar),
+		CalendarDates: make(map[string][]*CalendarDate),
+		Shapes:        make(map[string][]ShapePoint),
+	}
+
+	for _, file := range feedFiles {
+		t, closer, err := openTable(z, file.name)
+		if nil != err {
+			return nil, err
+		}
+		if nil == t {
+			continue
+		}
+		file.read(t, f)
+		closer.Close()
+		if nil != t.err {
+			return nil, t.err
+		}
+	}
+
+	if err := f.index(); nil != err {
+		return nil, err
+	}
+	return f, nil
+}
+
+// index links stop times to their trips and stops, and trips to their
+// routes, then orders them.
+func (f *Feed) index() error {
+	for _, st := range f.stopTimes {
+		trip, ok := f.Trips[st.TripID]
+		if !ok {
+			return fmt.Errorf("stop_times.txt: unknown trip %q", st.TripID)
+		}
+		trip.StopTimes = append(trip.StopTimes, st)
+		if stop, ok := f.Stops[st.StopID]; ok {
+			stop.StopTimes = append(stop.StopTimes, st)
+		}
+	}
+	f.stopTimes = nil
+
+	for _, trip := range f.Trips {
+		if route, ok := f.Routes[trip.RouteID]; ok {
+			route.Trips = append(route.Trips, trip)
+		}
+		sort.Slice(trip.StopTimes, func(i, j int) bool {
+			return trip.StopTimes[i].Sequence < trip.StopTimes[j].Sequence
+		})
+	}
+	for _, route := range f.Routes {
+		sort.Slice(route.Trips, func(i, j int) bool {
+			return route.Trips[i].ID < route.Trips[j].ID
+		})
+	}
+	for _, stop := range f.Stops {
+		sort.SliceStable(stop.StopTimes, func(i, j int) bool {
+			return stop.StopTimes[i].Departure < stop.StopTimes[j].Departure
+		})
+	}
+	for _, points := range f.Shapes {
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].Sequence < points[j].Sequence
+		})
+	}
+	return nil
+}
+
+// StopByLocationID returns the stop for a TriMet location ID, such as
+// trimet.Location.ID.
+func (f *Feed) StopByLocationID(id int) (*Stop, bool) {
+	s, ok := f.Stops[strconv.Itoa(id)]
+	return s, ok
+}
+
+// RouteByID returns the route for a TriMet route number, such as
+// trimet.Route.ID.
+func (f *Feed) RouteByID(id int) (*Route, bool) {
+	r, ok := f.Routes[strconv.Itoa(id)]
+	return r, ok
+}
+
+// LocationStop returns the stop for l.
+func (f *Feed) LocationStop(l trimet.Location) (*Stop, bool) {
+	return f.StopByLocationID(l.ID)
+}
+
+// ServiceActive reports whether the service operates on date, taking
+// calendar date exceptions into account.
+func (f *Feed) ServiceActive(serviceID string, date time.Time) bool {
+	d := day(date)
+	for _, cd := range f.CalendarDates[serviceID] {
+		if cd.Date.Equal(d) {
+			return ServiceAdded == cd.ExceptionType
+		}
+	}
+	c, ok := f.Calendars[serviceID]
+	return ok && c.Includes(d)
+}
+
+// Schedule returns the stop times at the stop with the given TriMet location
+// ID on date, ordered by departure time.  Untimed stop times are omitted, as
+// they have no departure time to order by.
+func (f *Feed) Schedule(locationID int, date time.Time) []*StopTime {
+	stop, ok := f.StopByLocationID(locationID)
+	if !ok {
+		return nil
+	}
+
+	active := make(map[string]bool)
+	var stopTimes []*StopTime
+	for _, st := range stop.StopTimes {
+		serviceID := f.Trips[st.TripID].ServiceID
+		on, seen := active[serviceID]
+		if !seen {
+			on = f.ServiceActive(serviceID, date)
+			active[serviceID] = on
+		}
+		if on && st.Timed() {
+			stopTimes = append(stopTimes, st)
+		}
+	}
+	return stopTimes
+}
+
+// ScheduledTime returns the time of the stop time offset d on the service
+// day of date in loc, measured from noon minus twelve hours as GTFS requires.
+func ScheduledTime(date time.Time, d time.Duration, loc *time.Location) time.Time {
+	y, m, dd := date.In(loc).Date()
+	noon := time.Date(y, m, dd, 12, 0, 0, 0, loc)
+	return noon.Add(-12 * time.Hour).Add(d)
+}
@@ -0,0 +1,171 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// testFeed is a minimal TriMet feed with one route serving two stops.
+var testFeed = map[string]string{
+	"agency.txt": "\ufeffagency_id,agency_name,agency_url,agency_timezone\n" +
+		"TRIMET,TriMet,http://trimet.org,America/Los_Angeles\n",
+	"stops.txt": "stop_id,stop_name,stop_lat,stop_lon\n" +
+		"8989,NW 23rd & Marshall,45.5306116,-122.6986884\n" +
+		"10775,NW Glisan & 18th,45.5263,-122.6884\n",
+	"routes.txt": "route_id,agency_id,route_short_name,route_long_name,route_type\n" +
+		"15,TRIMET,15,Belmont/NW 23rd,3\n",
+	"trips.txt": "route_id,service_id,trip_id,direction_id,block_id,shape_id\n" +
+		"15,W,T2,1,1537,S1\n" +
+		"15,W,T1,1,1537,S1\n" +
+		"15,H,T3,1,1538,S1\n",
+	"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+		"T1,17:20:00,17:20:00,10775,2\n" +
+		"T1,17:14:00,17:15:00,8989,1\n" +
+		"T2,24:10:00,24:10:00,8989,1\n" +
+		"T3,09:00:00,09:00:00,8989,1\n",
+	"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+		"W,1,1,1,1,1,0,0,20140101,20141231\n",
+	"calendar_dates.txt": "service_id,date,exception_type\n" +
+		"W,20140120,2\n" +
+		"H,20140120,1\n",
+	"shapes.txt": "shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence\n" +
+		"S1,45.53,-122.69,2\n" +
+		"S1,45.52,-122.68,1\n",
+}
+
+// readTestFeed zips files and reads the archive as a feed.
+func readTestFeed(files map[string]string) (*Feed, error) {
+	buf := new(bytes.Buffer)
+	z := zip.NewWriter(buf)
+	for name, content := range files {
+		w, _ := z.Create(name)
+		w.Write([]byte(content))
+	}
+	z.Close()
+
+	return Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+func newTestFeed(t *testing.T, files map[string]string) *Feed {
+	f, err := readTestFeed(files)
+	if nil != err {
+		t.Fatalf("Unexpected error reading feed: %v", err)
+	}
+	return f
+}
+
+func TestRead(t *testing.T) {
+	f := newTestFeed(t, testFeed)
+
+	if 1 != len(f.Agencies) || "TRIMET" != f.Agencies[0].ID {
+		t.Errorf("Expected agency TRIMET, found %+v", f.Agencies)
+	}
+
+	stop, ok := f.LocationStop(trimet.Location{ID: 8989})
+	if !ok || "NW 23rd & Marshall" != stop.Name {
+		t.Fatalf("Expected stop 8989, found %+v", stop)
+	}
+
+	route, ok := f.RouteByID(15)
+	if !ok || RouteTypeBus != route.Type {
+		t.Fatalf("Expected bus route 15, found %+v", route)
+	}
+	var trips []string
+	for _, trip := range route.Trips {
+		trips = append(trips, trip.ID)
+	}
+	if expect := []string{"T1", "T2", "T3"}; !reflect.DeepEqual(expect, trips) {
+		t.Errorf("Expected route trips %v, found %v", expect, trips)
+	}
+
+	t1 := f.Trips["T1"]
+	if 2 != len(t1.StopTimes) || "8989" != t1.StopTimes[0].StopID {
+		t.Errorf("Expected T1 stop times ordered by sequence, found %+v", t1.StopTimes)
+	}
+	if 17*time.Hour+15*time.Minute != t1.StopTimes[0].Departure {
+		t.Errorf("Expected departure 17:15, found %v", t1.StopTimes[0].Departure)
+	}
+
+	if points := f.Shapes["S1"]; 2 != len(points) || 1 != points[0].Sequence {
+		t.Errorf("Expected shape points ordered by sequence, found %+v", points)
+	}
+}
+
+func TestFeed_Schedule(t *testing.T) {
+	f := newTestFeed(t, testFeed)
+
+	tripIDs := func(stopTimes []*StopTime) []string {
+		var ids []string
+		for _, st := range stopTimes {
+			ids = append(ids, st.TripID)
+		}
+		return ids
+	}
+
+	monday := time.Date(2014, 1, 13, 0, 0, 0, 0, time.UTC)
+	if expect, found := []string{"T1", "T2"}, tripIDs(f.Schedule(8989, monday)); !reflect.DeepEqual(expect, found) {
+		t.Errorf("Expected weekday schedule %v, found %v", expect, found)
+	}
+
+	holiday := time.Date(2014, 1, 20, 0, 0, 0, 0, time.UTC)
+	if expect, found := []string{"T3"}, tripIDs(f.Schedule(8989, holiday)); !reflect.DeepEqual(expect, found) {
+		t.Errorf("Expected holiday schedule %v, found %v", expect, found)
+	}
+
+	sunday := time.Date(2014, 1, 12, 0, 0, 0, 0, time.UTC)
+	if found := f.Schedule(8989, sunday); 0 != len(found) {
+		t.Errorf("Expected no service on Sunday, found %v", tripIDs(found))
+	}
+}
+
+func TestFeed_Schedule_untimed(t *testing.T) {
+	files := make(map[string]string)
+	for name, content := range testFeed {
+		files[name] = content
+	}
+	files["stop_times.txt"] += "T4,,,8989,2\n"
+	files["trips.txt"] += "15,W,T4,1,1539,S1\n"
+	f := newTestFeed(t, files)
+
+	st := f.Trips["T4"].StopTimes[0]
+	if st.Timed() || Untimed != st.Arrival || Untimed != st.Departure {
+		t.Errorf("Expected untimed stop time, found %+v", st)
+	}
+
+	monday := time.Date(2014, 1, 13, 0, 0, 0, 0, time.UTC)
+	for _, st := range f.Schedule(8989, monday) {
+		if "T4" == st.TripID {
+			t.Errorf("Expected untimed stop time to be omitted, found %+v", st)
+		}
+	}
+}
+
+func TestScheduledTime(t *testing.T) {
+	pdx, err := time.LoadLocation("America/Los_Angeles")
+	if nil != err {
+		t.Skip("Time zone data unavailable")
+	}
+
+	date := time.Date(2014, 1, 13, 8, 0, 0, 0, pdx)
+	at := ScheduledTime(date, 24*time.Hour+10*time.Minute, pdx)
+	if expect := time.Date(2014, 1, 14, 0, 10, 0, 0, pdx); !expect.Equal(at) {
+		t.Errorf("Expected %v, found %v", expect, at)
+	}
+}
+
+func TestRead_errors(t *testing.T) {
+	files := map[string]string{
+		"trips.txt":      "route_id,service_id,trip_id\n15,W,T1\n",
+		"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\nT1,5pm,5pm,8989,1\n",
+	}
+	_, err := readTestFeed(files)
+	if nil == err || !strings.Contains(err.Error(), "arrival_time") {
+		t.Errorf("Expected invalid arrival_time error, found %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package gtfs
+
+// A Route is a group of trips presented to riders as a single service.
+//
+// TriMet route IDs are the route numbers used by the web services.
+type Route struct {
+	ID        string
+	AgencyID  string
+	ShortName string
+	LongName  string
+	Type      int
+	URL       string
+	Color     string
+	TextColor string
+
+	// Trips serving the route.
+	Trips []*Trip
+}
+
+// Route types defined by GTFS.
+const (
+	RouteTypeTram      = 0
+	RouteTypeSubway    = 1
+	RouteTypeRail      = 2
+	RouteTypeBus       = 3
+	RouteTypeFerry     = 4
+	RouteTypeCableCar  = 5
+	RouteTypeGondola   = 6
+	RouteTypeFunicular = 7
+)
+
+func readRoutes(t *table, f *Feed) {
+	for t.next() {
+		r := &Route{
+			ID:        t.str("route_id"),
+			AgencyID:  t.str("agency_id"),
+			ShortName: t.str("route_short_name"),
+			LongName:  t.str("route_long_name"),
+			Type:      t.int("route_type"),
+			URL:       t.str("route_url"),
+			Color:     t.str("route_color"),
+			TextColor: t.str("route_text_color"),
+		}
+		f.Routes[r.ID] = r
+	}
+}
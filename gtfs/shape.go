@@ -0,0 +1,21 @@
+package gtfs
+
+// A ShapePoint is one point along the path travelled by a trip.
+type ShapePoint struct {
+	Lat          float64
+	Lon          float64
+	Sequence     int
+	DistTraveled float64
+}
+
+func readShapes(t *table, f *Feed) {
+	for t.next() {
+		id := t.str("shape_id")
+		f.Shapes[id] = append(f.Shapes[id], ShapePoint{
+			Lat:          t.float("shape_pt_lat"),
+			Lon:          t.float("shape_pt_lon"),
+			Sequence:     t.int("shape_pt_sequence"),
+			DistTraveled: t.float("shape_dist_traveled"),
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package gtfs
+
+// A Stop is a place where vehicles pick up or drop off riders.
+//
+// TriMet stop IDs are the location IDs used by the web services.
+type Stop struct {
+	ID            string
+	Code          string
+	Name          string
+	Description   string
+	Lat           float64
+	Lon           float64
+	ParentStation string
+	Wheelchair    int
+
+	// Stop times at the stop, ordered by departure time.
+	StopTimes []*StopTime
+}
+
+func readStops(t *table, f *Feed) {
+	for t.next() {
+		s := &Stop{
+			ID:            t.str("stop_id"),
+			Code:          t.str("stop_code"),
+			Name:          t.str("stop_name"),
+			Description:   t.str("stop_desc"),
+			Lat:           t.float("stop_lat"),
+			Lon:           t.float("stop_lon"),
+			ParentStation: t.str("parent_station"),
+			Wheelchair:    t.int("wheelchair_boarding"),
+		}
+		f.Stops[s.ID] = s
+	}
+}
@@ -0,0 +1,46 @@
+package gtfs
+
+import "time"
+
+// Untimed is the Arrival and Departure of a stop time without a scheduled
+// time, which GTFS allows at stops other than timepoints.
+const Untimed time.Duration = -1
+
+// A StopTime is the scheduled time a trip arrives at and departs from a stop.
+//
+// Times are measured from noon minus twelve hours on the service day, and
+// exceed 24 hours for trips which run past midnight.  Times left empty in the
+// feed are Untimed.
+type StopTime struct {
+	TripID    string
+	StopID    string
+	Sequence  int
+	Arrival   time.Duration
+	Departure time.Duration
+	Headsign  string
+	Timepoint bool
+
+	// Distance along the trip's shape, in the units of the feed's shapes.
+	DistTraveled float64
+}
+
+// Timed reports whether st has scheduled arrival and departure times.
+func (st *StopTime) Timed() bool {
+	return Untimed != st.Arrival && Untimed != st.Departure
+}
+
+func readStopTimes(t *table, f *Feed) {
+	for t.next() {
+		st := &StopTime{
+			TripID:       t.str("trip_id"),
+			StopID:       t.str("stop_id"),
+			Sequence:     t.int("stop_sequence"),
+			Arrival:      t.clock("arrival_time"),
+			Departure:    t.clock("departure_time"),
+			Headsign:     t.str("stop_headsign"),
+			Timepoint:    t.bool("timepoint"),
+			DistTraveled: t.float("shape_dist_traveled"),
+		}
+		f.stopTimes = append(f.stopTimes, st)
+	}
+}
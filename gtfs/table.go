@@ -0,0 +1,175 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A table reads the records of one GTFS file, addressing fields by the names
+// in its header.
+type table struct {
+	name   string
+	r      *csv.Reader
+	index  map[string]int
+	record []string
+	line   int
+	err    error
+}
+
+// openTable opens the file name in the archive z.  It returns nil, and no
+// error, if the file does not exist.
+func openTable(z *zip.Reader, name string) (*table, io.Closer, error) {
+	var f *zip.File
+	for _, zf := range z.File {
+		if name == zf.Name || strings.HasSuffix(zf.Name, "/"+name) {
+			f = zf
+			break
+		}
+	}
+	if nil == f {
+		return nil, nil, nil
+	}
+
+	rc, err := f.Open()
+	if nil != err {
+		return nil, nil, err
+	}
+
+	r := csv.NewReader(rc)
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+	header, err := r.Read()
+	if nil != err {
+		rc.Close()
+		if io.EOF == err {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	t := &table{name: name, r: r, index: make(map[string]int, len(header)), line: 1}
+	for i, h := range header {
+		if 0 == i {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		t.index[strings.TrimSpace(h)] = i
+	}
+	return t, rc, nil
+}
+
+// next advances to the next record, reporting whether there is one.
+func (t *table) next() bool {
+	if nil != t.err {
+		return false
+	}
+	record, err := t.r.Read()
+	if io.EOF == err {
+		return false
+	}
+	if nil != err {
+		t.err = fmt.Errorf("%s: %v", t.name, err)
+		return false
+	}
+	t.record = record
+	t.line++
+	return true
+}
+
+// str returns the named field of the current record, or "" if it is absent.
+func (t *table) str(field string) string {
+	i, ok := t.index[field]
+	if !ok || i >= len(t.record) {
+		return ""
+	}
+	return strings.TrimSpace(t.record[i])
+}
+
+// int returns the named field as an integer, or 0 if it is empty.
+func (t *table) int(field string) int {
+	s := t.str(field)
+	if "" == s {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if nil != err {
+		t.fail(field, err)
+	}
+	return n
+}
+
+// float returns the named field as a float, or 0 if it is empty.
+func (t *table) float(field string) float64 {
+	s := t.str(field)
+	if "" == s {
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if nil != err {
+		t.fail(field, err)
+	}
+	return f
+}
+
+// bool returns whether the named field is "1".
+func (t *table) bool(field string) bool {
+	return "1" == t.str(field)
+}
+
+// date returns the named field as a GTFS YYYYMMDD date in UTC.
+func (t *table) date(field string) time.Time {
+	s := t.str(field)
+	if "" == s {
+		return time.Time{}
+	}
+	d, err := time.Parse(dateLayout, s)
+	if nil != err {
+		t.fail(field, err)
+	}
+	return d
+}
+
+// clock returns the named field as a GTFS HH:MM:SS time since noon minus
+// twelve hours, which may exceed 24 hours for trips running past midnight, or
+// Untimed if it is empty.
+func (t *table) clock(field string) time.Duration {
+	s := t.str(field)
+	if "" == s {
+		return Untimed
+	}
+	d, err := parseClock(s)
+	if nil != err {
+		t.fail(field, err)
+	}
+	return d
+}
+
+func (t *table) fail(field string, err error) {
+	if nil == t.err {
+		t.err = fmt.Errorf("%s line %d: invalid %s: %v", t.name, t.line, field, err)
+	}
+}
+
+const dateLayout = "20060102"
+
+// parseClock parses a GTFS HH:MM:SS time.
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if 3 != len(parts) {
+		return 0, fmt.Errorf("malformed time %q", s)
+	}
+
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if nil != err || n < 0 {
+			return 0, fmt.Errorf("malformed time %q", s)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
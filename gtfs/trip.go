@@ -0,0 +1,30 @@
+package gtfs
+
+// A Trip is a sequence of stops made by a vehicle at scheduled times.
+type Trip struct {
+	ID          string
+	RouteID     string
+	ServiceID   string
+	Headsign    string
+	DirectionID int
+	BlockID     string
+	ShapeID     string
+
+	// Stop times of the trip, ordered by stop sequence.
+	StopTimes []*StopTime
+}
+
+func readTrips(t *table, f *Feed) {
+	for t.next() {
+		trip := &Trip{
+			ID:          t.str("trip_id"),
+			RouteID:     t.str("route_id"),
+			ServiceID:   t.str("service_id"),
+			Headsign:    t.str("trip_headsign"),
+			DirectionID: t.int("direction_id"),
+			BlockID:     t.str("block_id"),
+			ShapeID:     t.str("shape_id"),
+		}
+		f.Trips[trip.ID] = trip
+	}
+}
@@ -0,0 +1,124 @@
+package gtfsrt
+
+import "time"
+
+// Alert causes and effects used by this package.
+const (
+	CauseUnknown = 1
+
+	EffectNoService = 1
+	EffectDetour    = 4
+	EffectUnknown   = 8
+)
+
+// An Alert reports a service disruption.
+type Alert struct {
+	// ID of the feed entity.
+	ID string
+
+	ActivePeriods    []TimeRange
+	InformedEntities []EntitySelector
+
+	// Cause and effect of the alert, such as EffectDetour.
+	Cause  int
+	Effect int
+
+	// The first translation of each of the alert's texts.
+	URL         string
+	Header      string
+	Description string
+}
+
+// A TimeRange is a period during which an alert is in effect.  Either time
+// may be zero, meaning the period is open ended.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// An EntitySelector identifies the agency, route, trip or stop an alert
+// applies to.
+type EntitySelector struct {
+	AgencyID string
+	RouteID  string
+	TripID   string
+	StopID   string
+}
+
+func decodeAlert(b []byte) (*Alert, error) {
+	a := new(Alert)
+	err := walk(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			var r TimeRange
+			err = walk(f.bytes, func(f field) error {
+				switch f.num {
+				case 1:
+					r.Start = unixTime(f.int64())
+				case 2:
+					r.End = unixTime(f.int64())
+				}
+				return nil
+			})
+			a.ActivePeriods = append(a.ActivePeriods, r)
+		case 5:
+			var e EntitySelector
+			e, err = decodeEntitySelector(f.bytes)
+			a.InformedEntities = append(a.InformedEntities, e)
+		case 6:
+			a.Cause = f.int()
+		case 7:
+			a.Effect = f.int()
+		case 8:
+			a.URL, err = decodeTranslatedString(f.bytes)
+		case 10:
+			a.Header, err = decodeTranslatedString(f.bytes)
+		case 11:
+			a.Description, err = decodeTranslatedString(f.bytes)
+		}
+		return err
+	})
+	return a, err
+}
+
+func decodeEntitySelector(b []byte) (EntitySelector, error) {
+	var e EntitySelector
+	err := walk(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			e.AgencyID = f.str()
+		case 2:
+			e.RouteID = f.str()
+		case 4:
+			var trip TripDescriptor
+			trip, err = decodeTripDescriptor(f.bytes)
+			e.TripID = trip.TripID
+		case 5:
+			e.StopID = f.str()
+		}
+		return err
+	})
+	return e, err
+}
+
+// decodeTranslatedString returns the first translation of a
+// TranslatedString.
+func decodeTranslatedString(b []byte) (string, error) {
+	var text string
+	found := false
+	err := walk(b, func(f field) error {
+		if 1 != f.num || found {
+			return nil
+		}
+		found = true
+		return walk(f.bytes, func(f field) error {
+			if 1 == f.num {
+				text = f.str()
+			}
+			return nil
+		})
+	})
+	return text, err
+}
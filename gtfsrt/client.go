@@ -0,0 +1,64 @@
+package gtfsrt
+
+import (
+	"context"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// Paths of TriMet's GTFS-realtime feeds, relative to the trimet.Client's
+// BaseURL.
+const (
+	TripUpdatesPath      = "TripUpdate"
+	VehiclePositionsPath = "VehiclePositions"
+	AlertsPath           = "FeedSpecAlerts"
+)
+
+const protobufMediaType = "application/x-protobuf"
+
+// feedParams are the parameters of feed requests.  Feeds are protocol
+// buffers, so they opt out of the JSON format the trimet.Client requests by
+// default and keep its json parameter off the URL.
+var feedParams = &trimet.Request{XML: true}
+
+// A Client fetches GTFS-realtime feeds using a trimet.Client, so that feed
+// requests share its AppID, retry policy, rate limiter and interceptors.
+type Client struct {
+	client *trimet.Client
+}
+
+// NewClient returns a new GTFS-realtime client which sends requests with c.
+func NewClient(c *trimet.Client) *Client {
+	return &Client{client: c}
+}
+
+// TripUpdates fetches the trip updates feed.
+func (c *Client) TripUpdates(ctx context.Context) (*Feed, error) {
+	return c.Get(ctx, TripUpdatesPath)
+}
+
+// VehiclePositions fetches the vehicle positions feed.
+func (c *Client) VehiclePositions(ctx context.Context) (*Feed, error) {
+	return c.Get(ctx, VehiclePositionsPath)
+}
+
+// Alerts fetches the service alerts feed.
+func (c *Client) Alerts(ctx context.Context) (*Feed, error) {
+	return c.Get(ctx, AlertsPath)
+}
+
+// Get fetches and decodes the feed at path, relative to the trimet.Client's
+// BaseURL.
+func (c *Client) Get(ctx context.Context, path string) (*Feed, error) {
+	req, err := c.client.NewRequestContext(ctx, "GET", path, feedParams)
+	if nil != err {
+		return nil, err
+	}
+	req.Header.Set("Accept", protobufMediaType)
+
+	var data []byte
+	if _, err := c.client.Do(req, &data); nil != err {
+		return nil, err
+	}
+	return Decode(data)
+}
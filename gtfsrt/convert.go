@@ -0,0 +1,178 @@
+package gtfsrt
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// Arrivals returns the predicted arrivals at the given TriMet location IDs,
+// ordered by time, in the form reported by the arrivals web service.  If no
+// IDs are given, arrivals at every stop are returned.
+//
+// Predictions with neither an arrival nor departure time are omitted unless
+// the trip or stop is canceled.  Block numbers, signs and vehicle positions
+// are not reported by GTFS-realtime and are left empty.
+func (feed *Feed) Arrivals(locationIDs ...int) *trimet.ArrivalsResponse {
+	wanted := make(map[int]bool, len(locationIDs))
+	for _, id := range locationIDs {
+		wanted[id] = true
+	}
+
+	response := new(trimet.ArrivalsResponse)
+	if !feed.Timestamp.IsZero() {
+		response.QueryTime = trimet.NewTime(feed.Timestamp)
+	}
+
+	seen := make(map[int]bool)
+	for _, u := range feed.TripUpdates {
+		for _, stu := range u.StopTimeUpdates {
+			location, err := strconv.Atoi(stu.StopID)
+			if nil != err || (0 != len(wanted) && !wanted[location]) {
+				continue
+			}
+			a, ok := arrival(u, stu)
+			if !ok {
+				continue
+			}
+			a.Location = location
+			response.Arrivals = append(response.Arrivals, a)
+			seen[location] = true
+		}
+	}
+
+	if 0 == len(locationIDs) {
+		for id := range seen {
+			locationIDs = append(locationIDs, id)
+		}
+		sort.Ints(locationIDs)
+	}
+	for _, id := range locationIDs {
+		response.Locations = append(response.Locations, trimet.Location{ID: id})
+	}
+
+	sort.SliceStable(response.Arrivals, func(i, j int) bool {
+		return arrivalTime(response.Arrivals[i]) < arrivalTime(response.Arrivals[j])
+	})
+	return response
+}
+
+// arrival converts the prediction stu of trip update u to an Arrival.
+func arrival(u TripUpdate, stu StopTimeUpdate) (trimet.Arrival, bool) {
+	a := trimet.Arrival{
+		Direction: u.Trip.DirectionID,
+		Status:    "estimated",
+	}
+	a.Route, _ = strconv.Atoi(u.Trip.RouteID)
+
+	event := stu.Arrival
+	if nil == event || event.Time.IsZero() {
+		event = stu.Departure
+	}
+	if nil != event && !event.Time.IsZero() {
+		a.Estimated = trimet.NewTime(event.Time)
+		if event.HasDelay {
+			a.Scheduled = trimet.NewTime(event.Time.Add(-event.Delay))
+		}
+	} else {
+		event = nil
+	}
+
+	if TripCanceled == u.Trip.ScheduleRelationship || StopSkipped == stu.ScheduleRelationship {
+		a.Status = "canceled"
+		a.Estimated = nil
+		return a, true
+	}
+	return a, nil != event
+}
+
+// arrivalTime returns the best known time of a, in Unix nanoseconds.
+func arrivalTime(a trimet.Arrival) int64 {
+	for _, t := range []*trimet.Time{a.Estimated, a.Scheduled} {
+		if nil != t && nil != t.Time {
+			return t.UnixNano()
+		}
+	}
+	return math.MaxInt64
+}
+
+// Vehicles returns the vehicle positions in the form reported by the
+// vehicles web service.
+//
+// The stop a vehicle is stopped at is reported as its LastLocation, and the
+// stop it is approaching as its NextLocation.
+func (feed *Feed) Vehicles() []trimet.Vehicle {
+	var vehicles []trimet.Vehicle
+	for _, p := range feed.VehiclePositions {
+		v := trimet.Vehicle{
+			TripID:         p.Trip.TripID,
+			Direction:      p.Trip.DirectionID,
+			Lat:            p.Lat,
+			Lon:            p.Lon,
+			Bearing:        int(math.Round(p.Bearing)),
+			LoadPercentage: p.OccupancyPercentage,
+			InCongestion:   p.CongestionLevel >= StopAndGo,
+		}
+		v.ID, _ = strconv.Atoi(p.VehicleID)
+		v.Route, _ = strconv.Atoi(p.Trip.RouteID)
+		stop, _ := strconv.Atoi(p.StopID)
+		if StoppedAt == p.CurrentStatus {
+			v.LastLocation = stop
+		} else {
+			v.NextLocation = stop
+		}
+		if !p.Timestamp.IsZero() {
+			v.LocationTime = trimet.NewTime(p.Timestamp)
+		}
+		vehicles = append(vehicles, v)
+	}
+	return vehicles
+}
+
+// Alerts returns the service alerts in the form reported by the alerts web
+// service.  Only the first active period of each alert is reported.
+func (feed *Feed) Alerts() []trimet.Alert {
+	var alerts []trimet.Alert
+	for _, sa := range feed.ServiceAlerts {
+		a := trimet.Alert{
+			Header:      sa.Header,
+			Description: sa.Description,
+			InfoLink:    sa.URL,
+		}
+		a.ID, _ = strconv.Atoi(sa.ID)
+		if 0 != len(sa.ActivePeriods) {
+			if p := sa.ActivePeriods[0]; !p.Start.IsZero() {
+				a.Begin = trimet.NewTime(p.Start)
+			}
+			if p := sa.ActivePeriods[0]; !p.End.IsZero() {
+				a.End = trimet.NewTime(p.End)
+			}
+		}
+
+		routes := make(map[int]bool)
+		stops := make(map[int]bool)
+		for _, e := range sa.InformedEntities {
+			if "" != e.AgencyID && "" == e.RouteID && "" == e.TripID && "" == e.StopID {
+				a.SystemWide = true
+			}
+			if id, err := strconv.Atoi(e.RouteID); nil == err && !routes[id] {
+				routes[id] = true
+				a.Routes = append(a.Routes, trimet.Route{ID: id})
+			}
+			if id, err := strconv.Atoi(e.StopID); nil == err && !stops[id] {
+				stops[id] = true
+				a.Locations = append(a.Locations, trimet.Location{ID: id})
+			}
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts
+}
+
+// Detours returns the service alerts in the form reported by the detours web
+// service.
+func (feed *Feed) Detours() []trimet.Detour {
+	return trimet.AlertsToDetours(feed.Alerts())
+}
@@ -0,0 +1,207 @@
+package gtfsrt
+
+import (
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// specVersion is the GTFS-realtime version of encoded feeds.
+const specVersion = "2.0"
+
+// Encode encodes the feed as a GTFS-realtime FeedMessage.  Feeds are always
+// encoded as full datasets.
+func (feed *Feed) Encode() []byte {
+	version := feed.Version
+	if "" == version {
+		version = specVersion
+	}
+	header := appendString(nil, 1, version)
+	header = appendVarint(header, 2, 0)
+	header = appendTime(header, 3, feed.Timestamp)
+
+	b := appendMessage(nil, 1, header)
+	for i := range feed.TripUpdates {
+		u := &feed.TripUpdates[i]
+		b = appendEntity(b, u.ID, 3, u.encode())
+	}
+	for i := range feed.VehiclePositions {
+		v := &feed.VehiclePositions[i]
+		b = appendEntity(b, v.ID, 4, v.encode())
+	}
+	for i := range feed.ServiceAlerts {
+		a := &feed.ServiceAlerts[i]
+		b = appendEntity(b, a.ID, 5, a.encode())
+	}
+	return b
+}
+
+// appendEntity appends a FeedEntity with the given ID, holding the encoded
+// message m as field num.
+func appendEntity(b []byte, id string, num protowire.Number, m []byte) []byte {
+	entity := appendString(nil, 1, id)
+	entity = appendMessage(entity, num, m)
+	return appendMessage(b, 2, entity)
+}
+
+func (t *TripDescriptor) encode() []byte {
+	b := appendString(nil, 1, t.TripID)
+	b = appendString(b, 2, t.StartTime)
+	b = appendString(b, 3, t.StartDate)
+	if TripScheduled != t.ScheduleRelationship {
+		b = appendVarint(b, 4, int64(t.ScheduleRelationship))
+	}
+	b = appendString(b, 5, t.RouteID)
+	return appendVarint(b, 6, int64(t.DirectionID))
+}
+
+func encodeVehicleDescriptor(id, label string) []byte {
+	b := appendString(nil, 1, id)
+	return appendString(b, 2, label)
+}
+
+func (u *TripUpdate) encode() []byte {
+	b := appendMessage(nil, 1, u.Trip.encode())
+	for i := range u.StopTimeUpdates {
+		b = appendMessage(b, 2, u.StopTimeUpdates[i].encode())
+	}
+	if "" != u.VehicleID || "" != u.VehicleLabel {
+		b = appendMessage(b, 3, encodeVehicleDescriptor(u.VehicleID, u.VehicleLabel))
+	}
+	b = appendTime(b, 4, u.Timestamp)
+	if 0 != u.Delay {
+		b = appendVarint(b, 5, int64(u.Delay/time.Second))
+	}
+	return b
+}
+
+func (u *StopTimeUpdate) encode() []byte {
+	var b []byte
+	if 0 != u.StopSequence {
+		b = appendVarint(b, 1, int64(u.StopSequence))
+	}
+	if nil != u.Arrival {
+		b = appendMessage(b, 2, u.Arrival.encode())
+	}
+	if nil != u.Departure {
+		b = appendMessage(b, 3, u.Departure.encode())
+	}
+	b = appendString(b, 4, u.StopID)
+	if StopScheduled != u.ScheduleRelationship {
+		b = appendVarint(b, 5, int64(u.ScheduleRelationship))
+	}
+	return b
+}
+
+func (e *StopTimeEvent) encode() []byte {
+	var b []byte
+	if e.HasDelay {
+		b = appendVarint(b, 1, int64(e.Delay/time.Second))
+	}
+	b = appendTime(b, 2, e.Time)
+	if 0 != e.Uncertainty {
+		b = appendVarint(b, 3, int64(e.Uncertainty/time.Second))
+	}
+	return b
+}
+
+func (v *VehiclePosition) encode() []byte {
+	b := appendMessage(nil, 1, v.Trip.encode())
+
+	position := appendFloat(nil, 1, v.Lat)
+	position = appendFloat(position, 2, v.Lon)
+	position = appendFloat(position, 3, v.Bearing)
+	if 0 != v.Speed {
+		position = appendFloat(position, 5, v.Speed)
+	}
+	b = appendMessage(b, 2, position)
+
+	if 0 != v.CurrentStopSequence {
+		b = appendVarint(b, 3, int64(v.CurrentStopSequence))
+	}
+	b = appendVarint(b, 4, int64(v.CurrentStatus))
+	b = appendTime(b, 5, v.Timestamp)
+	if CongestionUnknown != v.CongestionLevel {
+		b = appendVarint(b, 6, int64(v.CongestionLevel))
+	}
+	b = appendString(b, 7, v.StopID)
+	if "" != v.VehicleID || "" != v.VehicleLabel {
+		b = appendMessage(b, 8, encodeVehicleDescriptor(v.VehicleID, v.VehicleLabel))
+	}
+	if 0 != v.OccupancyStatus {
+		b = appendVarint(b, 9, int64(v.OccupancyStatus))
+	}
+	if 0 != v.OccupancyPercentage {
+		b = appendVarint(b, 10, int64(v.OccupancyPercentage))
+	}
+	return b
+}
+
+func (a *Alert) encode() []byte {
+	var b []byte
+	for _, p := range a.ActivePeriods {
+		period := appendTime(nil, 1, p.Start)
+		period = appendTime(period, 2, p.End)
+		b = appendMessage(b, 1, period)
+	}
+	for _, e := range a.InformedEntities {
+		entity := appendString(nil, 1, e.AgencyID)
+		entity = appendString(entity, 2, e.RouteID)
+		if "" != e.TripID {
+			entity = appendMessage(entity, 4, appendString(nil, 1, e.TripID))
+		}
+		entity = appendString(entity, 5, e.StopID)
+		b = appendMessage(b, 5, entity)
+	}
+	if 0 != a.Cause {
+		b = appendVarint(b, 6, int64(a.Cause))
+	}
+	if 0 != a.Effect {
+		b = appendVarint(b, 7, int64(a.Effect))
+	}
+	b = appendTranslatedString(b, 8, a.URL)
+	b = appendTranslatedString(b, 10, a.Header)
+	return appendTranslatedString(b, 11, a.Description)
+}
+
+// appendString appends a string field, omitting it when empty.
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if "" == s {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendTranslatedString appends a TranslatedString with a single
+// untagged translation, omitting it when empty.
+func appendTranslatedString(b []byte, num protowire.Number, s string) []byte {
+	if "" == s {
+		return b
+	}
+	return appendMessage(b, num, appendMessage(nil, 1, appendString(nil, 1, s)))
+}
+
+func appendMessage(b []byte, num protowire.Number, m []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
+
+func appendVarint(b []byte, num protowire.Number, v int64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// appendTime appends a POSIX timestamp field, omitting it for the zero time.
+func appendTime(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	return appendVarint(b, num, t.Unix())
+}
+
+func appendFloat(b []byte, num protowire.Number, f float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(float32(f)))
+}
@@ -0,0 +1,107 @@
+// Package gtfsrt decodes TriMet's GTFS-realtime feeds of trip updates,
+// vehicle positions and service alerts.
+//
+// A single feed request reports predictions for every stop in the system,
+// which is far cheaper than polling the arrivals web service stop by stop.
+// Decoded feeds can be mapped onto the types returned by the web services
+// with Feed.Arrivals, Feed.Vehicles, Feed.Alerts and Feed.Detours, so that
+// code written against them can switch data sources.
+//
+// GTFS-realtime reference: https://gtfs.org/realtime/reference/
+package gtfsrt
+
+import (
+	"time"
+)
+
+// A Feed is a decoded GTFS-realtime FeedMessage.
+type Feed struct {
+	// Version of the GTFS-realtime specification the feed conforms to.
+	Version string
+
+	// Time the feed was generated.
+	Timestamp time.Time
+
+	TripUpdates      []TripUpdate
+	VehiclePositions []VehiclePosition
+	ServiceAlerts    []Alert
+}
+
+// Decode decodes a GTFS-realtime FeedMessage.
+func Decode(data []byte) (*Feed, error) {
+	feed := new(Feed)
+	err := walk(data, func(f field) error {
+		switch f.num {
+		case 1:
+			return walk(f.bytes, func(f field) error {
+				switch f.num {
+				case 1:
+					feed.Version = f.str()
+				case 3:
+					feed.Timestamp = unixTime(f.int64())
+				}
+				return nil
+			})
+		case 2:
+			return feed.decodeEntity(f.bytes)
+		}
+		return nil
+	})
+	if nil != err {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// decodeEntity decodes a FeedEntity, adding its trip update, vehicle position
+// or alert to the feed.
+func (feed *Feed) decodeEntity(b []byte) error {
+	var (
+		id      string
+		deleted bool
+		update  *TripUpdate
+		vehicle *VehiclePosition
+		alert   *Alert
+	)
+	err := walk(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			id = f.str()
+		case 2:
+			deleted = f.bool()
+		case 3:
+			update, err = decodeTripUpdate(f.bytes)
+		case 4:
+			vehicle, err = decodeVehiclePosition(f.bytes)
+		case 5:
+			alert, err = decodeAlert(f.bytes)
+		}
+		return err
+	})
+	if nil != err || deleted {
+		return err
+	}
+
+	if nil != update {
+		update.ID = id
+		feed.TripUpdates = append(feed.TripUpdates, *update)
+	}
+	if nil != vehicle {
+		vehicle.ID = id
+		feed.VehiclePositions = append(feed.VehiclePositions, *vehicle)
+	}
+	if nil != alert {
+		alert.ID = id
+		feed.ServiceAlerts = append(feed.ServiceAlerts, *alert)
+	}
+	return nil
+}
+
+// unixTime returns the time of a POSIX timestamp, or the zero time for 0.
+func unixTime(seconds int64) time.Time {
+	if 0 == seconds {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
@@ -0,0 +1,225 @@
+package gtfsrt
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// pb builds protocol buffer messages for tests.
+type pb []byte
+
+func (b pb) str(num protowire.Number, s string) pb {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func (b pb) varint(num protowire.Number, v int64) pb {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func (b pb) float(num protowire.Number, f float32) pb {
+	b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(f))
+}
+
+func (b pb) msg(num protowire.Number, m pb) pb {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
+
+var (
+	testNow  = time.Unix(1389575529, 0)
+	testTrip = pb{}.str(1, "4285964").str(5, "15").varint(6, 1)
+	testFeed = newTestFeed()
+)
+
+// newTestFeed encodes a feed with a trip update, a deleted entity, a vehicle
+// position and two alerts.
+func newTestFeed() pb {
+	tripUpdate := pb{}.
+		msg(1, testTrip).
+		msg(2, pb{}.varint(1, 1).str(4, "8989").
+			msg(2, pb{}.varint(1, 120).varint(2, testNow.Unix()+600))).
+		msg(2, pb{}.varint(1, 2).str(4, "10775").
+			msg(3, pb{}.varint(1, -30).varint(2, testNow.Unix()+300))).
+		msg(2, pb{}.varint(1, 3).str(4, "7787").varint(5, StopSkipped)).
+		msg(3, pb{}.str(1, "2904"))
+
+	vehicle := pb{}.
+		msg(1, testTrip).
+		msg(2, pb{}.float(1, 45.5).float(2, -122.5).float(3, 272.6)).
+		varint(4, StoppedAt).
+		varint(5, testNow.Unix()-4).
+		varint(6, StopAndGo).
+		str(7, "8981").
+		msg(8, pb{}.str(1, "2904")).
+		varint(10, 42).
+		varint(99, 7)
+
+	stopClosed := pb{}.
+		msg(1, pb{}.varint(1, 1384207620).varint(2, 2141287200)).
+		msg(5, pb{}.str(2, "12")).
+		msg(5, pb{}.str(2, "12").str(5, "4305")).
+		msg(10, pb{}.msg(1, pb{}.str(1, "Stop closed"))).
+		msg(11, pb{}.
+			msg(1, pb{}.str(1, "No service to SW Pacific Hwy & 78th")).
+			msg(1, pb{}.str(1, "Sin servicio")))
+
+	snow := pb{}.
+		msg(5, pb{}.str(1, "TRIMET")).
+		msg(10, pb{}.msg(1, pb{}.str(1, "Snow")))
+
+	return pb{}.
+		msg(1, pb{}.str(1, "2.0").varint(3, testNow.Unix())).
+		msg(2, pb{}.str(1, "tu1").msg(3, tripUpdate)).
+		msg(2, pb{}.str(1, "deleted").varint(2, 1).msg(3, pb{}.msg(1, testTrip))).
+		msg(2, pb{}.str(1, "vp1").msg(4, vehicle)).
+		msg(2, pb{}.str(1, "28997").msg(5, stopClosed)).
+		msg(2, pb{}.str(1, "30001").msg(5, snow))
+}
+
+func TestDecode(t *testing.T) {
+	feed, err := Decode(testFeed)
+	if nil != err {
+		t.Fatalf("Unexpected error decoding feed: %v", err)
+	}
+
+	if "2.0" != feed.Version || !testNow.Equal(feed.Timestamp) {
+		t.Errorf("Expected version 2.0 at %v, found %v at %v", testNow, feed.Version, feed.Timestamp)
+	}
+	if 1 != len(feed.TripUpdates) || 1 != len(feed.VehiclePositions) || 2 != len(feed.ServiceAlerts) {
+		t.Fatalf("Expected 1 trip update, 1 vehicle and 2 alerts, found %+v", feed)
+	}
+
+	u := feed.TripUpdates[0]
+	if "tu1" != u.ID || "2904" != u.VehicleID || 3 != len(u.StopTimeUpdates) {
+		t.Errorf("Unexpected trip update %+v", u)
+	}
+	if e := u.StopTimeUpdates[1].Departure; nil == e || -30*time.Second != e.Delay {
+		t.Errorf("Expected departure delay of -30s, found %+v", e)
+	}
+	if v := feed.VehiclePositions[0]; float32(45.5) != float32(v.Lat) || 42 != v.OccupancyPercentage {
+		t.Errorf("Unexpected vehicle position %+v", v)
+	}
+}
+
+func TestDecode_malformed(t *testing.T) {
+	if _, err := Decode(testFeed[:len(testFeed)-3]); nil == err {
+		t.Error("Expected error decoding truncated feed")
+	}
+}
+
+func TestFeed_Arrivals(t *testing.T) {
+	feed, _ := Decode(testFeed)
+	arrivals := feed.Arrivals(8989, 10775, 7787)
+
+	at := func(offset, delay time.Duration) *trimet.Time {
+		return trimet.NewTime(testNow.Add(offset - delay))
+	}
+	expect := &trimet.ArrivalsResponse{
+		Response:  trimet.Response{QueryTime: trimet.NewTime(testNow)},
+		Locations: []trimet.Location{{ID: 8989}, {ID: 10775}, {ID: 7787}},
+		Arrivals: []trimet.Arrival{
+			{Location: 10775, Route: 15, Direction: 1, Status: "estimated", Estimated: at(5*time.Minute, 0), Scheduled: at(5*time.Minute, -30*time.Second)},
+			{Location: 8989, Route: 15, Direction: 1, Status: "estimated", Estimated: at(10*time.Minute, 0), Scheduled: at(10*time.Minute, 2*time.Minute)},
+			{Location: 7787, Route: 15, Direction: 1, Status: "canceled"},
+		},
+	}
+	if !reflect.DeepEqual(expect, arrivals) {
+		t.Errorf("Expected arrivals:\n%+v\nfound:\n%+v", expect, arrivals)
+	}
+
+	if all := feed.Arrivals(); 3 != len(all.Locations) || 7787 != all.Locations[0].ID {
+		t.Errorf("Expected arrivals at every stop, found %+v", all.Locations)
+	}
+}
+
+func TestFeed_Vehicles(t *testing.T) {
+	feed, _ := Decode(testFeed)
+
+	expect := []trimet.Vehicle{{
+		ID:             2904,
+		TripID:         "4285964",
+		Route:          15,
+		Direction:      1,
+		Lat:            float64(float32(45.5)),
+		Lon:            float64(float32(-122.5)),
+		Bearing:        273,
+		LastLocation:   8981,
+		LoadPercentage: 42,
+		InCongestion:   true,
+		LocationTime:   trimet.NewTime(testNow.Add(-4 * time.Second)),
+	}}
+	if vehicles := feed.Vehicles(); !reflect.DeepEqual(expect, vehicles) {
+		t.Errorf("Expected vehicles:\n%+v\nfound:\n%+v", expect, vehicles)
+	}
+}
+
+func TestFeed_Alerts(t *testing.T) {
+	feed, _ := Decode(testFeed)
+
+	expect := []trimet.Alert{
+		{
+			ID:          28997,
+			Header:      "Stop closed",
+			Description: "No service to SW Pacific Hwy & 78th",
+			Begin:       trimet.NewTime(time.Unix(1384207620, 0)),
+			End:         trimet.NewTime(time.Unix(2141287200, 0)),
+			Routes:      []trimet.Route{{ID: 12}},
+			Locations:   []trimet.Location{{ID: 4305}},
+		},
+		{ID: 30001, Header: "Snow", SystemWide: true},
+	}
+	if alerts := feed.Alerts(); !reflect.DeepEqual(expect, alerts) {
+		t.Errorf("Expected alerts:\n%+v\nfound:\n%+v", expect, alerts)
+	}
+
+	if detours := feed.Detours(); 2 != len(detours) || "28997" != detours[0].ID {
+		t.Errorf("Expected detours for each alert, found %+v", detours)
+	}
+}
+
+func TestClient_TripUpdates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "/TripUpdate" != r.URL.Path || "abc123" != r.FormValue("appID") {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if protobufMediaType != r.Header.Get("Accept") {
+			t.Errorf("Expected Accept %v, found %v", protobufMediaType, r.Header.Get("Accept"))
+		}
+		if _, ok := r.URL.Query()["json"]; ok {
+			t.Errorf("Expected no json parameter, found %v", r.URL.RawQuery)
+		}
+		w.Write(testFeed)
+	}))
+	defer server.Close()
+
+	c := trimet.NewClient("abc123", nil)
+	c.BaseURL, _ = c.BaseURL.Parse(server.URL + "/")
+	rt := NewClient(c)
+
+	feed, err := rt.TripUpdates(context.Background())
+	if nil != err {
+		t.Fatalf("TripUpdates returned error: %v", err)
+	}
+	if 1 != len(feed.TripUpdates) {
+		t.Errorf("Expected 1 trip update, found %+v", feed.TripUpdates)
+	}
+
+	_, err = rt.Alerts(context.Background())
+	var statusErr *trimet.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Errorf("Expected StatusError for missing feed, found %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package gtfsrt
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// A Source produces the feed served by a Handler.
+type Source func(ctx context.Context) (*Feed, error)
+
+// Handler returns an http.Handler which serves the feed produced by source
+// as a GTFS-realtime protocol buffer.  If source fails the handler responds
+// with 502 Bad Gateway, as the feed is derived from TriMet's services.
+func Handler(source Source) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "GET" != r.Method && "HEAD" != r.Method {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		feed, err := source(r.Context())
+		if nil != err {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		data := feed.Encode()
+		w.Header().Set("Content-Type", protobufMediaType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if !feed.Timestamp.IsZero() {
+			w.Header().Set("Last-Modified", feed.Timestamp.UTC().Format(http.TimeFormat))
+		}
+		if "HEAD" != r.Method {
+			w.Write(data)
+		}
+	})
+}
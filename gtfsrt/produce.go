@@ -0,0 +1,232 @@
+package gtfsrt
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// TripUpdatesFromArrivals builds a trip updates feed from arrivals
+// snapshots, such as the responses for several groups of stops.
+//
+// Arrivals are grouped into trips by the trip the vehicle is serving when it
+// reaches the stop, which is the last trip of its BlockPosition.  Arrivals
+// without position information cannot be matched to a trip and are left out.
+// Where snapshots report the same trip at a stop, the arrival from the newest
+// snapshot is used.  Estimated arrivals report their delay relative to the
+// schedule; arrivals which could not be estimated are reported as having no
+// data, and canceled arrivals as skipped.  Each trip's stops are ordered by
+// arrival time, as stop sequences are not known.
+func TripUpdatesFromArrivals(snapshots ...*trimet.ArrivalsResponse) *Feed {
+	feed := &Feed{Timestamp: latestQueryTime(snapshots)}
+
+	index := make(map[string]int)
+	var arrivals [][]snapshotArrival
+	for _, snapshot := range snapshots {
+		if nil == snapshot {
+			continue
+		}
+		var queried time.Time
+		if nil != snapshot.QueryTime && nil != snapshot.QueryTime.Time {
+			queried = *snapshot.QueryTime.Time
+		}
+		for _, a := range snapshot.Arrivals {
+			id, trip := arrivalTrip(a)
+			if "" == id {
+				continue
+			}
+			i, ok := index[id]
+			if !ok {
+				i = len(feed.TripUpdates)
+				index[id] = i
+				arrivals = append(arrivals, nil)
+				feed.TripUpdates = append(feed.TripUpdates, TripUpdate{
+					ID:           id,
+					Trip:         trip,
+					VehicleLabel: blockLabel(a.Block),
+				})
+			}
+			arrivals[i] = addSnapshotArrival(arrivals[i], snapshotArrival{a, queried})
+
+			u := &feed.TripUpdates[i]
+			if at := a.BlockPosition.At; nil != at && nil != at.Time && at.After(u.Timestamp) {
+				u.Timestamp = *at.Time
+			}
+		}
+	}
+
+	for i, trip := range arrivals {
+		sort.SliceStable(trip, func(i, j int) bool {
+			return arrivalTime(trip[i].Arrival) < arrivalTime(trip[j].Arrival)
+		})
+		for _, a := range trip {
+			feed.TripUpdates[i].StopTimeUpdates = append(feed.TripUpdates[i].StopTimeUpdates, stopTimeUpdate(a.Arrival))
+		}
+	}
+	return feed
+}
+
+// A snapshotArrival is an arrival along with the query time of the snapshot
+// reporting it.
+type snapshotArrival struct {
+	trimet.Arrival
+	queried time.Time
+}
+
+// addSnapshotArrival adds a to the arrivals of a trip, replacing the arrival
+// at the same stop unless it was reported by a newer snapshot.
+func addSnapshotArrival(trip []snapshotArrival, a snapshotArrival) []snapshotArrival {
+	for i := range trip {
+		if trip[i].Location == a.Location {
+			if !a.queried.Before(trip[i].queried) {
+				trip[i] = a
+			}
+			return trip
+		}
+	}
+	return append(trip, a)
+}
+
+// VehiclePositionsFromArrivals builds a vehicle positions feed from the
+// block positions reported with arrivals.  Vehicles are identified by their
+// block, as the version 1 arrivals service does not report vehicle IDs, and
+// the most recent position of each block is used.  Arrivals without a block
+// are identified by the trip the vehicle is serving instead.
+func VehiclePositionsFromArrivals(snapshots ...*trimet.ArrivalsResponse) *Feed {
+	feed := &Feed{Timestamp: latestQueryTime(snapshots)}
+
+	index := make(map[string]int)
+	for _, snapshot := range snapshots {
+		if nil == snapshot {
+			continue
+		}
+		for _, a := range snapshot.Arrivals {
+			p := a.BlockPosition
+			if nil == p.At || nil == p.At.Time {
+				continue
+			}
+
+			_, trip := arrivalTrip(a)
+			if 0 != len(p.Trips) {
+				trip.TripID = strconv.Itoa(p.Trips[0].ID)
+				trip.RouteID = strconv.Itoa(p.Trips[0].Route)
+				trip.DirectionID = p.Trips[0].Direction
+			}
+			id := blockLabel(a.Block)
+			if "" == id {
+				if "" == trip.TripID {
+					continue
+				}
+				id = "trip-" + trip.TripID
+			}
+			v := VehiclePosition{
+				ID:            id,
+				Trip:          trip,
+				VehicleID:     blockLabel(a.Block),
+				VehicleLabel:  blockLabel(a.Block),
+				Lat:           p.Lat,
+				Lon:           p.Lon,
+				Bearing:       float64(p.Heading),
+				CurrentStatus: InTransitTo,
+				Timestamp:     *p.At.Time,
+			}
+
+			i, ok := index[id]
+			switch {
+			case !ok:
+				index[id] = len(feed.VehiclePositions)
+				feed.VehiclePositions = append(feed.VehiclePositions, v)
+			case v.Timestamp.After(feed.VehiclePositions[i].Timestamp):
+				feed.VehiclePositions[i] = v
+			}
+		}
+	}
+	return feed
+}
+
+// AlertsFromDetours builds a service alerts feed from detours.  Each detour
+// is reported as a detour affecting its routes.
+func AlertsFromDetours(detours *trimet.DetoursResponse) *Feed {
+	feed := new(Feed)
+	if nil == detours {
+		return feed
+	}
+	if nil != detours.QueryTime && nil != detours.QueryTime.Time {
+		feed.Timestamp = *detours.QueryTime.Time
+	}
+
+	for _, d := range detours.Detours {
+		a := Alert{
+			ID:          d.ID,
+			Cause:       CauseUnknown,
+			Effect:      EffectDetour,
+			Description: d.Description,
+		}
+		period := TimeRange{}
+		if nil != d.Begin && nil != d.Begin.Time {
+			period.Start = *d.Begin.Time
+		}
+		if nil != d.End && nil != d.End.Time {
+			period.End = *d.End.Time
+		}
+		if !period.Start.IsZero() || !period.End.IsZero() {
+			a.ActivePeriods = []TimeRange{period}
+		}
+		for _, r := range d.Routes {
+			a.InformedEntities = append(a.InformedEntities, EntitySelector{RouteID: strconv.Itoa(r.ID)})
+		}
+		feed.ServiceAlerts = append(feed.ServiceAlerts, a)
+	}
+	return feed
+}
+
+// arrivalTrip returns the entity ID and trip descriptor of the trip serving
+// arrival a.  The ID is empty if the trip is not known.
+func arrivalTrip(a trimet.Arrival) (string, TripDescriptor) {
+	trip := TripDescriptor{
+		RouteID:     strconv.Itoa(a.Route),
+		DirectionID: a.Direction,
+	}
+	if trips := a.BlockPosition.Trips; 0 != len(trips) {
+		trip.TripID = strconv.Itoa(trips[len(trips)-1].ID)
+	}
+	return trip.TripID, trip
+}
+
+func blockLabel(block int) string {
+	if 0 == block {
+		return ""
+	}
+	return strconv.Itoa(block)
+}
+
+// stopTimeUpdate returns the prediction of arrival a at its stop.
+func stopTimeUpdate(a trimet.Arrival) StopTimeUpdate {
+	u := StopTimeUpdate{StopID: strconv.Itoa(a.Location)}
+	switch {
+	case "canceled" == a.Status:
+		u.ScheduleRelationship = StopSkipped
+	case nil != a.Estimated && nil != a.Estimated.Time:
+		u.Arrival = &StopTimeEvent{Time: *a.Estimated.Time}
+		if nil != a.Scheduled && nil != a.Scheduled.Time {
+			u.Arrival.Delay = a.Estimated.Sub(*a.Scheduled.Time)
+			u.Arrival.HasDelay = true
+		}
+	default:
+		u.ScheduleRelationship = StopNoData
+	}
+	return u
+}
+
+// latestQueryTime returns the latest query time of the snapshots.
+func latestQueryTime(snapshots []*trimet.ArrivalsResponse) time.Time {
+	var latest time.Time
+	for _, s := range snapshots {
+		if nil != s && nil != s.QueryTime && nil != s.QueryTime.Time && s.QueryTime.After(latest) {
+			latest = *s.QueryTime.Time
+		}
+	}
+	return latest
+}
@@ -0,0 +1,208 @@
+package gtfsrt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+func readArrivals(t *testing.T) *trimet.ArrivalsResponse {
+	b, err := ioutil.ReadFile("../testdata/arrivals.json")
+	if nil != err {
+		t.Fatal("Unable to read ../testdata/arrivals.json")
+	}
+	results := new(struct {
+		Results *trimet.ArrivalsResponse `json:"resultSet"`
+	})
+	if err := json.Unmarshal(b, results); nil != err {
+		t.Fatalf("Unable to decode arrivals: %v", err)
+	}
+	return results.Results
+}
+
+func TestFeed_Encode(t *testing.T) {
+	feed, _ := Decode(testFeed)
+	decoded, err := Decode(feed.Encode())
+	if nil != err {
+		t.Fatalf("Unexpected error decoding encoded feed: %v", err)
+	}
+	if !reflect.DeepEqual(feed, decoded) {
+		t.Errorf("Expected encoded feed to round trip:\n%+v\nfound:\n%+v", feed, decoded)
+	}
+}
+
+func TestTripUpdatesFromArrivals(t *testing.T) {
+	arrivals := readArrivals(t)
+	scheduled := arrivals.Arrivals[0].Scheduled.Add(-2 * time.Minute)
+	updated := arrivals.Arrivals[0]
+	updated.Estimated = trimet.NewTime(arrivals.Arrivals[0].Estimated.Add(30 * time.Second))
+	later := &trimet.ArrivalsResponse{
+		Response: trimet.Response{QueryTime: trimet.NewTime(arrivals.QueryTime.Add(time.Second))},
+		Arrivals: []trimet.Arrival{
+			updated,
+			{Location: 10775, Route: 15, Block: 1537, Status: "estimated",
+				Estimated: trimet.NewTime(scheduled.Add(time.Minute)), Scheduled: trimet.NewTime(scheduled),
+				BlockPosition: arrivals.Arrivals[0].BlockPosition},
+			{Location: 8989, Route: 12, Block: 1201, Status: "scheduled", Scheduled: trimet.NewTime(scheduled),
+				BlockPosition: trimet.Position{Trips: []trimet.Trip{{ID: 4285001, Route: 12}}}},
+			{Location: 8989, Route: 12, Block: 1202, Status: "canceled", Scheduled: trimet.NewTime(scheduled),
+				BlockPosition: trimet.Position{Trips: []trimet.Trip{{ID: 4285002, Route: 12}}}},
+			{Location: 8989, Route: 12, Block: 1203, Status: "scheduled", Scheduled: trimet.NewTime(scheduled)},
+		},
+	}
+
+	feed := TripUpdatesFromArrivals(arrivals, later)
+	if !later.QueryTime.Equal(feed.Timestamp) {
+		t.Errorf("Expected latest query time %v, found %v", later.QueryTime, feed.Timestamp)
+	}
+
+	expect := []TripUpdate{
+		{
+			ID:           "4285964",
+			Trip:         TripDescriptor{TripID: "4285964", RouteID: "15", DirectionID: 1},
+			VehicleLabel: "1537",
+			Timestamp:    *arrivals.Arrivals[0].BlockPosition.At.Time,
+			StopTimeUpdates: []StopTimeUpdate{
+				{StopID: "10775", Arrival: &StopTimeEvent{Time: scheduled.Add(time.Minute), Delay: time.Minute, HasDelay: true}},
+				{StopID: "8989", Arrival: &StopTimeEvent{Time: *updated.Estimated.Time, Delay: 30 * time.Second, HasDelay: true}},
+			},
+		},
+		{
+			ID:              "4285001",
+			Trip:            TripDescriptor{TripID: "4285001", RouteID: "12"},
+			VehicleLabel:    "1201",
+			StopTimeUpdates: []StopTimeUpdate{{StopID: "8989", ScheduleRelationship: StopNoData}},
+		},
+		{
+			ID:              "4285002",
+			Trip:            TripDescriptor{TripID: "4285002", RouteID: "12"},
+			VehicleLabel:    "1202",
+			StopTimeUpdates: []StopTimeUpdate{{StopID: "8989", ScheduleRelationship: StopSkipped}},
+		},
+	}
+	if !reflect.DeepEqual(expect, feed.TripUpdates) {
+		t.Errorf("Expected trip updates:\n%+v\nfound:\n%+v", expect, feed.TripUpdates)
+	}
+}
+
+func TestVehiclePositionsFromArrivals(t *testing.T) {
+	feed := VehiclePositionsFromArrivals(readArrivals(t))
+
+	expect := []VehiclePosition{{
+		ID:            "1537",
+		Trip:          TripDescriptor{TripID: "4285706", RouteID: "15", DirectionID: 0},
+		VehicleID:     "1537",
+		VehicleLabel:  "1537",
+		Lat:           45.5233678,
+		Lon:           -122.6973469,
+		Bearing:       273,
+		CurrentStatus: InTransitTo,
+		Timestamp:     time.Date(2014, 1, 12, 17, 12, 5, 0, time.FixedZone("", -8*60*60)),
+	}}
+	if 1 != len(feed.VehiclePositions) || !expect[0].Timestamp.Equal(feed.VehiclePositions[0].Timestamp) {
+		t.Fatalf("Expected vehicle positions:\n%+v\nfound:\n%+v", expect, feed.VehiclePositions)
+	}
+	// Compare the remaining fields regardless of time zone.
+	feed.VehiclePositions[0].Timestamp = expect[0].Timestamp
+	if !reflect.DeepEqual(expect, feed.VehiclePositions) {
+		t.Errorf("Expected vehicle positions:\n%+v\nfound:\n%+v", expect, feed.VehiclePositions)
+	}
+}
+
+func TestFromArrivals_withoutBlock(t *testing.T) {
+	scheduled := time.Date(2014, 1, 12, 17, 30, 0, 0, time.UTC)
+	position := trimet.Position{At: trimet.NewTime(scheduled.Add(-10 * time.Minute)), Lat: 45.52, Lon: -122.69,
+		Trips: []trimet.Trip{{ID: 4285706, Route: 12, Direction: 1}}}
+	arrivals := &trimet.ArrivalsResponse{Arrivals: []trimet.Arrival{
+		{Location: 8989, Route: 12, Direction: 1, Status: "scheduled", Scheduled: trimet.NewTime(scheduled)},
+		{Location: 8989, Route: 12, Direction: 1, Status: "scheduled", Scheduled: trimet.NewTime(scheduled.Add(15 * time.Minute))},
+		{Location: 10775, Route: 12, Direction: 1, Status: "scheduled"},
+		{Location: 10775, Route: 12, Direction: 1, Status: "estimated", Scheduled: trimet.NewTime(scheduled),
+			Estimated: trimet.NewTime(scheduled), BlockPosition: position},
+	}}
+
+	var ids []string
+	for _, u := range TripUpdatesFromArrivals(arrivals).TripUpdates {
+		ids = append(ids, u.ID)
+	}
+	expect := []string{"4285706"}
+	if !reflect.DeepEqual(expect, ids) {
+		t.Errorf("Expected trip update IDs %v, found %v", expect, ids)
+	}
+
+	ids = nil
+	for _, v := range VehiclePositionsFromArrivals(arrivals).VehiclePositions {
+		ids = append(ids, v.ID)
+	}
+	if expect := []string{"trip-4285706"}; !reflect.DeepEqual(expect, ids) {
+		t.Errorf("Expected vehicle position IDs %v, found %v", expect, ids)
+	}
+}
+
+func TestAlertsFromDetours(t *testing.T) {
+	begin := time.Unix(1384207620, 0)
+	detours := &trimet.DetoursResponse{
+		Detours: []trimet.Detour{{
+			ID:          "28997",
+			Begin:       trimet.NewTime(begin),
+			Description: "No service to SW Pacific Hwy & 78th",
+			Routes:      []trimet.Route{{ID: 12}, {ID: 94}},
+		}},
+	}
+
+	expect := []Alert{{
+		ID:               "28997",
+		ActivePeriods:    []TimeRange{{Start: begin}},
+		InformedEntities: []EntitySelector{{RouteID: "12"}, {RouteID: "94"}},
+		Cause:            CauseUnknown,
+		Effect:           EffectDetour,
+		Description:      "No service to SW Pacific Hwy & 78th",
+	}}
+	feed := AlertsFromDetours(detours)
+	if !reflect.DeepEqual(expect, feed.ServiceAlerts) {
+		t.Errorf("Expected alerts:\n%+v\nfound:\n%+v", expect, feed.ServiceAlerts)
+	}
+
+	decoded, _ := Decode(feed.Encode())
+	if detours := decoded.Detours(); 1 != len(detours) || "28997" != detours[0].ID || 2 != len(detours[0].Routes) {
+		t.Errorf("Expected encoded alerts to map back to detours, found %+v", detours)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var sourceErr error
+	server := httptest.NewServer(Handler(func(ctx context.Context) (*Feed, error) {
+		return AlertsFromDetours(&trimet.DetoursResponse{
+			Detours: []trimet.Detour{{ID: "1", Routes: []trimet.Route{{ID: 12}}}},
+		}), sourceErr
+	}))
+	defer server.Close()
+
+	c := trimet.NewClient("abc123", nil)
+	c.BaseURL, _ = c.BaseURL.Parse(server.URL + "/")
+	feed, err := NewClient(c).Alerts(context.Background())
+	if nil != err {
+		t.Fatalf("Alerts returned error: %v", err)
+	}
+	if 1 != len(feed.ServiceAlerts) || "1" != feed.ServiceAlerts[0].ID || specVersion != feed.Version {
+		t.Errorf("Expected served alert, found %+v", feed)
+	}
+
+	sourceErr = errors.New("unavailable")
+	res, err := http.Get(server.URL)
+	if nil != err {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	res.Body.Close()
+	if http.StatusBadGateway != res.StatusCode {
+		t.Errorf("Expected status %d when source fails, found %d", http.StatusBadGateway, res.StatusCode)
+	}
+}
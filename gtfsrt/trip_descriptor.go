@@ -0,0 +1,61 @@
+package gtfsrt
+
+// Trip schedule relationships.
+const (
+	TripScheduled   = 0
+	TripAdded       = 1
+	TripUnscheduled = 2
+	TripCanceled    = 3
+)
+
+// A TripDescriptor identifies the trip a vehicle is serving.
+type TripDescriptor struct {
+	TripID      string
+	RouteID     string
+	DirectionID int
+
+	// Scheduled start time of the trip, in HH:MM:SS local time.
+	StartTime string
+
+	// Service date of the trip, in YYYYMMDD format.
+	StartDate string
+
+	// Relationship of the trip to the static schedule, such as TripCanceled.
+	ScheduleRelationship int
+}
+
+func decodeTripDescriptor(b []byte) (TripDescriptor, error) {
+	var t TripDescriptor
+	err := walk(b, func(f field) error {
+		switch f.num {
+		case 1:
+			t.TripID = f.str()
+		case 2:
+			t.StartTime = f.str()
+		case 3:
+			t.StartDate = f.str()
+		case 4:
+			t.ScheduleRelationship = f.int()
+		case 5:
+			t.RouteID = f.str()
+		case 6:
+			t.DirectionID = f.int()
+		}
+		return nil
+	})
+	return t, err
+}
+
+// decodeVehicleDescriptor returns the ID and label of a VehicleDescriptor.
+func decodeVehicleDescriptor(b []byte) (id, label string, err error) {
+	err = walk(b, func(f field) error {
+		switch f.num {
+		case 1:
+			id = f.str()
+		case 2:
+			label = f.str()
+		}
+		return nil
+	})
+	return id, label, err
+}
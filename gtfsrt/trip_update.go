@@ -0,0 +1,118 @@
+package gtfsrt
+
+import "time"
+
+// Stop time schedule relationships.
+const (
+	StopScheduled = 0
+	StopSkipped   = 1
+	StopNoData    = 2
+)
+
+// A TripUpdate reports realtime progress of a trip.
+type TripUpdate struct {
+	// ID of the feed entity.
+	ID string
+
+	Trip         TripDescriptor
+	VehicleID    string
+	VehicleLabel string
+
+	// Time the prediction was last updated.
+	Timestamp time.Time
+
+	// Current delay of the trip.
+	Delay time.Duration
+
+	// Predictions for the trip's stops, ordered by stop sequence.
+	StopTimeUpdates []StopTimeUpdate
+}
+
+// A StopTimeUpdate predicts the arrival and departure of a trip at one stop.
+type StopTimeUpdate struct {
+	StopSequence int
+	StopID       string
+
+	// Predicted arrival and departure, either of which may be nil.
+	Arrival   *StopTimeEvent
+	Departure *StopTimeEvent
+
+	// Relationship of the stop to the static schedule, such as StopSkipped.
+	ScheduleRelationship int
+}
+
+// A StopTimeEvent is a predicted arrival or departure.
+type StopTimeEvent struct {
+	// Predicted delay relative to the schedule.
+	Delay time.Duration
+
+	// Predicted time, or the zero time if only the delay is known.
+	Time time.Time
+
+	// Expected error in the prediction, or zero if unknown.
+	Uncertainty time.Duration
+
+	// Whether Delay was reported.
+	HasDelay bool
+}
+
+func decodeTripUpdate(b []byte) (*TripUpdate, error) {
+	u := new(TripUpdate)
+	err := walk(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			u.Trip, err = decodeTripDescriptor(f.bytes)
+		case 2:
+			var stu StopTimeUpdate
+			stu, err = decodeStopTimeUpdate(f.bytes)
+			u.StopTimeUpdates = append(u.StopTimeUpdates, stu)
+		case 3:
+			u.VehicleID, u.VehicleLabel, err = decodeVehicleDescriptor(f.bytes)
+		case 4:
+			u.Timestamp = unixTime(f.int64())
+		case 5:
+			u.Delay = time.Duration(f.int()) * time.Second
+		}
+		return err
+	})
+	return u, err
+}
+
+func decodeStopTimeUpdate(b []byte) (StopTimeUpdate, error) {
+	var u StopTimeUpdate
+	err := walk(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			u.StopSequence = f.int()
+		case 2:
+			u.Arrival, err = decodeStopTimeEvent(f.bytes)
+		case 3:
+			u.Departure, err = decodeStopTimeEvent(f.bytes)
+		case 4:
+			u.StopID = f.str()
+		case 5:
+			u.ScheduleRelationship = f.int()
+		}
+		return err
+	})
+	return u, err
+}
+
+func decodeStopTimeEvent(b []byte) (*StopTimeEvent, error) {
+	e := new(StopTimeEvent)
+	err := walk(b, func(f field) error {
+		switch f.num {
+		case 1:
+			e.Delay = time.Duration(f.int()) * time.Second
+			e.HasDelay = true
+		case 2:
+			e.Time = unixTime(f.int64())
+		case 3:
+			e.Uncertainty = time.Duration(f.int()) * time.Second
+		}
+		return nil
+	})
+	return e, err
+}
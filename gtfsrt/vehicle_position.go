@@ -0,0 +1,92 @@
+package gtfsrt
+
+import "time"
+
+// Vehicle stop statuses.
+const (
+	IncomingAt  = 0
+	StoppedAt   = 1
+	InTransitTo = 2
+)
+
+// Congestion levels.
+const (
+	CongestionUnknown = 0
+	RunningSmoothly   = 1
+	StopAndGo         = 2
+	Congestion        = 3
+	SevereCongestion  = 4
+)
+
+// A VehiclePosition reports the realtime position of a vehicle.
+type VehiclePosition struct {
+	// ID of the feed entity.
+	ID string
+
+	Trip         TripDescriptor
+	VehicleID    string
+	VehicleLabel string
+
+	Lat     float64
+	Lon     float64
+	Bearing float64
+
+	// Speed in meters per second.
+	Speed float64
+
+	// The stop the vehicle is at or approaching, as described by
+	// CurrentStatus.
+	StopID              string
+	CurrentStopSequence int
+	CurrentStatus       int
+
+	CongestionLevel     int
+	OccupancyStatus     int
+	OccupancyPercentage int
+
+	// Time the position was measured.
+	Timestamp time.Time
+}
+
+func decodeVehiclePosition(b []byte) (*VehiclePosition, error) {
+	v := &VehiclePosition{CurrentStatus: InTransitTo}
+	err := walk(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			v.Trip, err = decodeTripDescriptor(f.bytes)
+		case 2:
+			err = walk(f.bytes, func(f field) error {
+				switch f.num {
+				case 1:
+					v.Lat = f.float()
+				case 2:
+					v.Lon = f.float()
+				case 3:
+					v.Bearing = f.float()
+				case 5:
+					v.Speed = f.float()
+				}
+				return nil
+			})
+		case 3:
+			v.CurrentStopSequence = f.int()
+		case 4:
+			v.CurrentStatus = f.int()
+		case 5:
+			v.Timestamp = unixTime(f.int64())
+		case 6:
+			v.CongestionLevel = f.int()
+		case 7:
+			v.StopID = f.str()
+		case 8:
+			v.VehicleID, v.VehicleLabel, err = decodeVehicleDescriptor(f.bytes)
+		case 9:
+			v.OccupancyStatus = f.int()
+		case 10:
+			v.OccupancyPercentage = f.int()
+		}
+		return err
+	})
+	return v, err
+}
@@ -0,0 +1,77 @@
+package gtfsrt
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// A field is a single decoded protocol buffer field.
+type field struct {
+	num   protowire.Number
+	typ   protowire.Type
+	value uint64
+	bytes []byte
+}
+
+// walk calls fn for each field of the protocol buffer message in b.  Unknown
+// fields, which fn simply ignores, are skipped as GTFS-realtime extensions
+// require.
+func walk(b []byte, fn func(f field) error) error {
+	for 0 < len(b) {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.value = uint64(v)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(f); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f field) str() string {
+	return string(f.bytes)
+}
+
+func (f field) int() int {
+	return int(int32(f.value))
+}
+
+func (f field) int64() int64 {
+	return int64(f.value)
+}
+
+func (f field) bool() bool {
+	return 0 != f.value
+}
+
+// float returns the value of a float field, or of a double field.
+func (f field) float() float64 {
+	if protowire.Fixed64Type == f.typ {
+		return math.Float64frombits(f.value)
+	}
+	return float64(math.Float32frombits(uint32(f.value)))
+}
@@ -0,0 +1,182 @@
+package headway
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// DefaultWindow is the period over which an Analyzer reports frequency when
+// none is given.
+const DefaultWindow = time.Hour
+
+// A Headway is the interval between consecutive arrivals of a group.
+type Headway struct {
+	// The arrivals either side of the headway.
+	Previous, Next *trimet.Arrival
+
+	// The interval between their scheduled times.  It is negative if the
+	// arrivals are predicted out of scheduled order.
+	Scheduled time.Duration
+
+	// The interval between their predicted times: estimated where
+	// available, and scheduled otherwise.
+	Predicted time.Duration
+
+	// Gap is set if the predicted headway is longer than the scheduled
+	// headway of the group, allowing for the Analyzer's Tolerance.
+	Gap bool
+}
+
+// An Analysis reports the headways of a group of arrivals.
+type Analysis struct {
+	Key
+
+	// The arrivals expected to be served, in predicted order.  Canceled
+	// arrivals are excluded.
+	Arrivals []trimet.Arrival
+
+	// The typical scheduled headway, which is the median interval between
+	// the scheduled times of every arrival, including those canceled.  It
+	// is zero if fewer than two arrivals are scheduled.
+	ScheduledHeadway time.Duration
+
+	// The headways between consecutive Arrivals.
+	Headways []Headway
+
+	// The number of Arrivals predicted within the Analyzer's Window.
+	Frequency int
+
+	// The Window over which Frequency was counted.
+	Window time.Duration
+}
+
+// Gaps returns the headways flagged as gaps.
+func (a *Analysis) Gaps() []Headway {
+	var gaps []Headway
+	for _, h := range a.Headways {
+		if h.Gap {
+			gaps = append(gaps, h)
+		}
+	}
+	return gaps
+}
+
+// EffectiveHeadway returns the average interval between arrivals within the
+// window, judged by Frequency, or zero if none are predicted.
+func (a *Analysis) EffectiveHeadway() time.Duration {
+	if 0 == a.Frequency {
+		return 0
+	}
+	return a.Window / time.Duration(a.Frequency)
+}
+
+// An Analyzer analyses the headways of arrivals.  The zero value is ready to
+// use.
+type Analyzer struct {
+	// Fraction by which a predicted headway may exceed the scheduled
+	// headway before it is flagged as a gap.  For instance, 0.5 flags
+	// headways half as long again as scheduled.
+	Tolerance float64
+
+	// Period from the time of analysis over which frequency is counted.
+	// Defaults to DefaultWindow if not positive.
+	Window time.Duration
+}
+
+// Analyze analyses the arrivals in response as of its QueryTime, using the
+// zero Analyzer.
+func Analyze(response *trimet.ArrivalsResponse) []Analysis {
+	return new(Analyzer).Analyze(response)
+}
+
+// Analyze analyses the arrivals in response as of its QueryTime, or the
+// current time if it has none.  A nil response has no analyses.
+func (a *Analyzer) Analyze(response *trimet.ArrivalsResponse) []Analysis {
+	if nil == response {
+		return nil
+	}
+	now := time.Now()
+	if nil != response.QueryTime && nil != response.QueryTime.Time {
+		now = *response.QueryTime.Time
+	}
+	return a.AnalyzeArrivals(response.Arrivals, now)
+}
+
+// AnalyzeArrivals analyses arrivals as of now, returning an Analysis of each
+// group in the order of GroupArrivals.
+func (a *Analyzer) AnalyzeArrivals(arrivals []trimet.Arrival, now time.Time) []Analysis {
+	groups := GroupArrivals(arrivals)
+	analyses := make([]Analysis, len(groups))
+	for i, g := range groups {
+		analyses[i] = a.analyze(g, now)
+	}
+	return analyses
+}
+
+// analyze analyses the arrivals of g, which are in scheduled order.
+func (a *Analyzer) analyze(g Group, now time.Time) Analysis {
+	window := a.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	analysis := Analysis{
+		Key:              g.Key,
+		ScheduledHeadway: medianHeadway(g.Arrivals),
+		Window:           window,
+	}
+
+	for _, arrival := range g.Arrivals {
+		if !canceled(&arrival) {
+			analysis.Arrivals = append(analysis.Arrivals, arrival)
+		}
+	}
+	sort.SliceStable(analysis.Arrivals, func(i, j int) bool {
+		return predictedTime(&analysis.Arrivals[i]).Before(predictedTime(&analysis.Arrivals[j]))
+	})
+
+	limit := analysis.ScheduledHeadway + time.Duration(a.Tolerance*float64(analysis.ScheduledHeadway))
+	for i := 1; i < len(analysis.Arrivals); i++ {
+		previous, next := &analysis.Arrivals[i-1], &analysis.Arrivals[i]
+		h := Headway{
+			Previous:  previous,
+			Next:      next,
+			Scheduled: scheduledTime(next).Sub(scheduledTime(previous)),
+			Predicted: predictedTime(next).Sub(predictedTime(previous)),
+		}
+		h.Gap = 0 < analysis.ScheduledHeadway && h.Predicted > limit
+		analysis.Headways = append(analysis.Headways, h)
+	}
+
+	end := now.Add(window)
+	for i := range analysis.Arrivals {
+		if t := predictedTime(&analysis.Arrivals[i]); !t.Before(now) && t.Before(end) {
+			analysis.Frequency++
+		}
+	}
+	return analysis
+}
+
+// medianHeadway returns the median interval between the scheduled times of
+// arrivals, which are in scheduled order.
+func medianHeadway(arrivals []trimet.Arrival) time.Duration {
+	var headways []time.Duration
+	for i := 1; i < len(arrivals); i++ {
+		previous, next := scheduledTime(&arrivals[i-1]), scheduledTime(&arrivals[i])
+		if previous.IsZero() || next.IsZero() {
+			continue
+		}
+		headways = append(headways, next.Sub(previous))
+	}
+	if 0 == len(headways) {
+		return 0
+	}
+
+	sort.Slice(headways, func(i, j int) bool { return headways[i] < headways[j] })
+	middle := len(headways) / 2
+	if 0 == len(headways)%2 {
+		return (headways[middle-1] + headways[middle]) / 2
+	}
+	return headways[middle]
+}
@@ -0,0 +1,123 @@
+package headway
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+var pst = time.FixedZone("PST", -8*60*60)
+
+// at returns the time at hour:minute on the day of the test arrivals.
+func at(hour, minute int) *trimet.Time {
+	return trimet.NewTime(time.Date(2014, 1, 12, hour, minute, 0, 0, pst))
+}
+
+func testArrivals() []trimet.Arrival {
+	arrival := func(route, scheduled int, estimated *trimet.Time, status string) trimet.Arrival {
+		return trimet.Arrival{Location: 8989, Route: route, Direction: 1,
+			Status: status, Scheduled: at(17, scheduled), Estimated: estimated}
+	}
+	return []trimet.Arrival{
+		arrival(15, 30, at(17, 33), "estimated"),
+		arrival(77, 25, nil, "scheduled"),
+		arrival(15, 20, at(17, 22), "estimated"),
+		arrival(15, 40, nil, "canceled"),
+		arrival(15, 50, at(17, 52), "estimated"),
+		arrival(15, 60, nil, "scheduled"),
+		arrival(15, 90, nil, "scheduled"),
+	}
+}
+
+func TestGroupArrivals(t *testing.T) {
+	groups := GroupArrivals(testArrivals())
+	if 2 != len(groups) {
+		t.Fatalf("Expected 2 groups, found %+v", groups)
+	}
+	if expect := (Key{8989, 15, 1}); expect != groups[0].Key {
+		t.Errorf("Expected first group %+v, found %+v", expect, groups[0].Key)
+	}
+	if 77 != groups[1].Route || 1 != len(groups[1].Arrivals) {
+		t.Errorf("Expected second group of route 77, found %+v", groups[1])
+	}
+
+	var scheduled []time.Time
+	for _, a := range groups[0].Arrivals {
+		scheduled = append(scheduled, *a.Scheduled.Time)
+	}
+	for i := 1; i < len(scheduled); i++ {
+		if scheduled[i].Before(scheduled[i-1]) {
+			t.Errorf("Expected arrivals in scheduled order, found %v", scheduled)
+		}
+	}
+}
+
+func headways(a Analysis) (predicted []time.Duration, gaps []bool) {
+	for _, h := range a.Headways {
+		predicted = append(predicted, h.Predicted)
+		gaps = append(gaps, h.Gap)
+	}
+	return predicted, gaps
+}
+
+func TestAnalyze(t *testing.T) {
+	response := &trimet.ArrivalsResponse{Arrivals: testArrivals()}
+	response.QueryTime = at(17, 12)
+
+	analyses := Analyze(response)
+	if 2 != len(analyses) {
+		t.Fatalf("Expected 2 analyses, found %+v", analyses)
+	}
+	if nil != Analyze(nil) {
+		t.Error("Expected no analyses of a nil response")
+	}
+
+	a := analyses[0]
+	if 10*time.Minute != a.ScheduledHeadway {
+		t.Errorf("Expected scheduled headway 10m, found %v", a.ScheduledHeadway)
+	}
+	if 5 != len(a.Arrivals) {
+		t.Errorf("Expected canceled arrival to be excluded, found %v arrivals", len(a.Arrivals))
+	}
+
+	predicted, gaps := headways(a)
+	expectPredicted := []time.Duration{11 * time.Minute, 19 * time.Minute, 8 * time.Minute, 30 * time.Minute}
+	if !reflect.DeepEqual(expectPredicted, predicted) {
+		t.Errorf("Expected predicted headways %v, found %v", expectPredicted, predicted)
+	}
+	if expect := []bool{true, true, false, true}; !reflect.DeepEqual(expect, gaps) {
+		t.Errorf("Expected gaps %v, found %v", expect, gaps)
+	}
+	if 20*time.Minute != a.Headways[1].Scheduled {
+		t.Errorf("Expected scheduled headway across canceled arrival of 20m, found %v", a.Headways[1].Scheduled)
+	}
+
+	if 4 != a.Frequency {
+		t.Errorf("Expected 4 arrivals in the next hour, found %v", a.Frequency)
+	}
+	if 15*time.Minute != a.EffectiveHeadway() {
+		t.Errorf("Expected effective headway 15m, found %v", a.EffectiveHeadway())
+	}
+
+	if 0 != analyses[1].ScheduledHeadway || 0 != len(analyses[1].Headways) {
+		t.Errorf("Expected no headways for a single arrival, found %+v", analyses[1])
+	}
+	if 1 != analyses[1].Frequency {
+		t.Errorf("Expected 1 arrival in the next hour, found %v", analyses[1].Frequency)
+	}
+}
+
+func TestAnalyzer_tolerance(t *testing.T) {
+	analyzer := &Analyzer{Tolerance: 1, Window: 30 * time.Minute}
+	analyses := analyzer.AnalyzeArrivals(testArrivals(), *at(17, 12).Time)
+
+	a := analyses[0]
+	if gaps := a.Gaps(); 1 != len(gaps) || 30*time.Minute != gaps[0].Predicted {
+		t.Errorf("Expected only the 30m headway to be a gap, found %+v", gaps)
+	}
+	if 2 != a.Frequency || 15*time.Minute != a.EffectiveHeadway() {
+		t.Errorf("Expected 2 arrivals in 30m, found %v every %v", a.Frequency, a.EffectiveHeadway())
+	}
+}
@@ -0,0 +1,222 @@
+package headway
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+const (
+	// DefaultBunchingThreshold is the fraction of the scheduled headway
+	// below which a BunchingDetector reports vehicles as bunched when none
+	// is given.
+	DefaultBunchingThreshold = 0.25
+
+	// DefaultSpeed is the average speed of vehicles, in feet per second,
+	// assumed by a BunchingDetector when none is given.  It is about 12
+	// miles per hour.
+	DefaultSpeed = 18
+)
+
+// An Incident reports two consecutive vehicles of a route, in one direction,
+// running closer together than scheduled.
+type Incident struct {
+	// The route and direction, and the first stop both vehicles are due to
+	// serve while bunched.
+	Key
+
+	// The blocks of the leading and following vehicles.
+	Blocks [2]int
+
+	// The arrivals of the leading and following vehicles at the stop.
+	// Their BlockPositions locate the vehicles.
+	Leader, Follower *trimet.Arrival
+
+	// The interval between their predicted arrivals at the stop.
+	Spacing time.Duration
+
+	// The distance between the vehicles, or zero if their positions cannot
+	// be compared.
+	Distance trimet.Distance
+
+	// The scheduled interval between the vehicles.
+	ScheduledHeadway time.Duration
+
+	// The distance covered in the scheduled interval at the detector's
+	// Speed.
+	ScheduledDistance trimet.Distance
+
+	// How closely the vehicles are spaced compared with the schedule: the
+	// ratio of Distance to ScheduledDistance if their positions can be
+	// compared, and of Spacing to ScheduledHeadway otherwise.
+	Ratio float64
+
+	// How badly the vehicles are bunched, from 0 at the detector's
+	// threshold up to 1 when they arrive together.
+	Severity float64
+}
+
+// A BunchingDetector finds bunched vehicles among arrivals.  The zero value
+// is ready to use.
+type BunchingDetector struct {
+	// Fraction of the scheduled headway below which consecutive vehicles
+	// are bunched.  Defaults to DefaultBunchingThreshold if not positive.
+	Threshold float64
+
+	// Average speed of vehicles, in feet per second, which converts the
+	// scheduled headway into the distance vehicles should be apart.
+	// Defaults to DefaultSpeed if not positive.
+	Speed float64
+}
+
+// DetectBunching reports bunched vehicles among the arrivals in response,
+// using the zero BunchingDetector.  A nil response has no incidents.
+func DetectBunching(response *trimet.ArrivalsResponse) []Incident {
+	if nil == response {
+		return nil
+	}
+	return new(BunchingDetector).Detect(response.Arrivals)
+}
+
+// Detect reports the bunched vehicles among arrivals.  Only arrivals with a
+// reported vehicle position are considered.
+//
+// Consecutive vehicles are compared by the distance between their
+// positions, as predicted arrival times lag behind vehicles which have
+// caught up with each other.  Vehicles whose positions cannot be compared
+// are judged by their predicted arrival times instead.
+//
+// A pair of vehicles bunched on their way to several stops is reported once,
+// at the stop they are due to serve first, even if they are predicted to
+// pass each other.  Incidents are ordered by route, direction and then
+// predicted arrival of the leading vehicle.
+func (d *BunchingDetector) Detect(arrivals []trimet.Arrival) []Incident {
+	threshold := d.Threshold
+	if threshold <= 0 {
+		threshold = DefaultBunchingThreshold
+	}
+
+	var located []trimet.Arrival
+	for _, a := range arrivals {
+		if nil != a.BlockPosition.At && nil != a.BlockPosition.At.Time && !canceled(&a) {
+			located = append(located, a)
+		}
+	}
+
+	type pair struct {
+		route, direction int
+		blocks           [2]int
+	}
+	var incidents []Incident
+	indexes := make(map[pair]int)
+	for _, g := range GroupArrivals(located) {
+		analysis := new(Analyzer).analyze(g, time.Time{})
+		for _, h := range analysis.Headways {
+			incident, ok := d.bunched(analysis, h, threshold)
+			if !ok {
+				continue
+			}
+
+			p := pair{g.Route, g.Direction, incident.Blocks}
+			if p.blocks[0] > p.blocks[1] {
+				p.blocks[0], p.blocks[1] = p.blocks[1], p.blocks[0]
+			}
+			if i, ok := indexes[p]; ok {
+				if predictedTime(h.Previous).Before(predictedTime(incidents[i].Leader)) {
+					incidents[i] = incident
+				}
+				continue
+			}
+			indexes[p] = len(incidents)
+			incidents = append(incidents, incident)
+		}
+	}
+
+	sort.SliceStable(incidents, func(i, j int) bool {
+		a, b := incidents[i], incidents[j]
+		switch {
+		case a.Route != b.Route:
+			return a.Route < b.Route
+		case a.Direction != b.Direction:
+			return a.Direction < b.Direction
+		}
+		return predictedTime(a.Leader).Before(predictedTime(b.Leader))
+	})
+	return incidents
+}
+
+// bunched returns the incident for headway h of analysis, and whether its
+// vehicles are bunched.
+func (d *BunchingDetector) bunched(analysis Analysis, h Headway, threshold float64) (Incident, bool) {
+	leader, follower := h.Previous, h.Next
+	if leader.Block == follower.Block {
+		return Incident{}, false
+	}
+
+	scheduled := h.Scheduled
+	if scheduled <= 0 {
+		scheduled = analysis.ScheduledHeadway
+	}
+	if scheduled <= 0 {
+		return Incident{}, false
+	}
+
+	speed := d.Speed
+	if speed <= 0 {
+		speed = DefaultSpeed
+	}
+	incident := Incident{
+		Key:               analysis.Key,
+		Blocks:            [2]int{leader.Block, follower.Block},
+		Leader:            leader,
+		Follower:          follower,
+		Spacing:           h.Predicted,
+		ScheduledHeadway:  scheduled,
+		ScheduledDistance: trimet.Distance(scheduled.Seconds() * speed),
+		Ratio:             float64(h.Predicted) / float64(scheduled),
+	}
+
+	distance, ok := distanceBetween(leader, follower)
+	if ok {
+		incident.Distance = distance
+		incident.Ratio = float64(distance) / float64(incident.ScheduledDistance)
+	}
+	if incident.Ratio >= threshold {
+		return Incident{}, false
+	}
+	incident.Severity = 1 - incident.Ratio/threshold
+	return incident, true
+}
+
+// distanceBetween returns the distance between the vehicles of two arrivals
+// at the same stop, and whether their positions can be compared.  It is
+// measured from their distances to the stop, or else their progress along
+// the same trip pattern, or else in a straight line between them.
+func distanceBetween(a, b *trimet.Arrival) (trimet.Distance, bool) {
+	p, q := a.BlockPosition, b.BlockPosition
+	if 0 < p.Feet && 0 < q.Feet {
+		return trimet.Distance(math.Abs(float64(p.Feet - q.Feet))), true
+	}
+
+	if 0 != len(p.Trips) && 0 != len(q.Trips) && p.Trips[0].Pattern == q.Trips[0].Pattern {
+		return trimet.Distance(math.Abs(float64(p.Trips[0].Progress - q.Trips[0].Progress))), true
+	}
+
+	if (0 == p.Lat && 0 == p.Lon) || (0 == q.Lat && 0 == q.Lon) {
+		return 0, false
+	}
+	return distanceFeet(p.Lat, p.Lon, q.Lat, q.Lon), true
+}
+
+// distanceFeet returns the great circle distance between two points.
+func distanceFeet(lat1, lon1, lat2, lon2 float64) trimet.Distance {
+	const earthRadiusFeet = 20902231
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat, dLon := rad(lat2-lat1), rad(lon2-lon1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return trimet.Distance(2 * earthRadiusFeet * math.Asin(math.Sqrt(h)))
+}
@@ -0,0 +1,144 @@
+package headway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// locatedArrival returns an estimated arrival of block at location, from a
+// vehicle feet from the stop.
+func locatedArrival(location, block, scheduled, estimated int, feet trimet.Distance) trimet.Arrival {
+	a := trimet.Arrival{Location: location, Route: 15, Direction: 1, Block: block,
+		Status: "estimated", Scheduled: at(17, scheduled), Estimated: at(17, estimated)}
+	a.BlockPosition.At = at(17, 12)
+	a.BlockPosition.Feet = feet
+	return a
+}
+
+func bunchingArrivals() []trimet.Arrival {
+	return []trimet.Arrival{
+		locatedArrival(8990, 1501, 25, 30, 5000),
+		locatedArrival(8990, 1502, 35, 32, 6000),
+		locatedArrival(8989, 1501, 20, 25, 3000),
+		locatedArrival(8989, 1502, 30, 27, 4000),
+		locatedArrival(8989, 1503, 40, 41, 9000),
+		{Location: 8989, Route: 15, Direction: 1, Block: 1504, Status: "scheduled",
+			Scheduled: at(17, 42)},
+	}
+}
+
+func TestDetectBunching(t *testing.T) {
+	response := &trimet.ArrivalsResponse{Arrivals: bunchingArrivals()}
+	incidents := DetectBunching(response)
+	if 1 != len(incidents) {
+		t.Fatalf("Expected 1 incident, found %+v", incidents)
+	}
+	if nil != DetectBunching(nil) {
+		t.Error("Expected no incidents for a nil response")
+	}
+
+	incident := incidents[0]
+	if expect := (Key{8989, 15, 1}); expect != incident.Key {
+		t.Errorf("Expected incident at %+v, found %+v", expect, incident.Key)
+	}
+	if expect := [2]int{1501, 1502}; expect != incident.Blocks {
+		t.Errorf("Expected blocks %v, found %v", expect, incident.Blocks)
+	}
+	if 1501 != incident.Leader.Block || 1502 != incident.Follower.Block {
+		t.Errorf("Expected block 1501 to lead 1502, found %v and %v", incident.Leader.Block, incident.Follower.Block)
+	}
+	if expect := trimet.Distance(1000); expect != incident.Distance {
+		t.Errorf("Expected vehicles %v feet apart, found %v", expect, incident.Distance)
+	}
+	if expect := trimet.Distance(10800); expect != incident.ScheduledDistance {
+		t.Errorf("Expected scheduled distance %v, found %v", expect, incident.ScheduledDistance)
+	}
+	if ratio := incident.Ratio; ratio < 0.092 || ratio > 0.093 {
+		t.Errorf("Expected ratio of 1000 to 10800 feet, found %v", ratio)
+	}
+	if severity := incident.Severity; severity < 0.62 || severity > 0.63 {
+		t.Errorf("Expected severity 0.63, found %v", severity)
+	}
+}
+
+func TestBunchingDetector_threshold(t *testing.T) {
+	detector := &BunchingDetector{Threshold: 0.05}
+	if incidents := detector.Detect(bunchingArrivals()); 0 != len(incidents) {
+		t.Errorf("Expected no incidents below threshold, found %+v", incidents)
+	}
+
+	detector.Threshold = 1.5
+	if incidents := detector.Detect(bunchingArrivals()); 2 != len(incidents) {
+		t.Errorf("Expected 2 incidents, found %+v", incidents)
+	}
+}
+
+func TestDetectBunching_positions(t *testing.T) {
+	// Estimates 10 minutes apart, as scheduled, but the vehicles are only
+	// 400 feet apart.
+	arrivals := []trimet.Arrival{
+		locatedArrival(8989, 1501, 20, 20, 2000),
+		locatedArrival(8989, 1502, 30, 30, 2400),
+	}
+	incidents := new(BunchingDetector).Detect(arrivals)
+	if 1 != len(incidents) {
+		t.Fatalf("Expected 1 incident, found %+v", incidents)
+	}
+	if 400 != incidents[0].Distance || 10*time.Minute != incidents[0].Spacing {
+		t.Errorf("Expected vehicles 400 feet and 10m apart, found %+v", incidents[0])
+	}
+
+	// Estimates 1 minute apart, but the vehicles are far apart.
+	arrivals = []trimet.Arrival{
+		locatedArrival(8989, 1501, 20, 29, 2000),
+		locatedArrival(8989, 1502, 30, 30, 12000),
+	}
+	if incidents := new(BunchingDetector).Detect(arrivals); 0 != len(incidents) {
+		t.Errorf("Expected no incidents for vehicles far apart, found %+v", incidents)
+	}
+}
+
+func TestDetectBunching_overtaking(t *testing.T) {
+	arrivals := []trimet.Arrival{
+		locatedArrival(8989, 1501, 20, 25, 3000),
+		locatedArrival(8989, 1502, 30, 26, 3500),
+		locatedArrival(8990, 1501, 25, 32, 5000),
+		locatedArrival(8990, 1502, 35, 31, 5500),
+	}
+	incidents := new(BunchingDetector).Detect(arrivals)
+	if 1 != len(incidents) {
+		t.Fatalf("Expected 1 incident for vehicles passing each other, found %+v", incidents)
+	}
+	if 8989 != incidents[0].Location {
+		t.Errorf("Expected incident at the first stop, found %v", incidents[0].Location)
+	}
+}
+
+func TestDistanceBetween(t *testing.T) {
+	a, b := new(trimet.Arrival), new(trimet.Arrival)
+	if _, ok := distanceBetween(a, b); ok {
+		t.Error("Expected positions without location not to be compared")
+	}
+
+	a.BlockPosition.Lat, a.BlockPosition.Lon = 45.5300, -122.6800
+	b.BlockPosition.Lat, b.BlockPosition.Lon = 45.5310, -122.6800
+	if d, ok := distanceBetween(a, b); !ok || d < 360 || d > 370 {
+		t.Errorf("Expected about 365 feet between coordinates, found %v", d)
+	}
+
+	a.BlockPosition.Trips = []trimet.Trip{{Pattern: 2, Progress: 12000}}
+	b.BlockPosition.Trips = []trimet.Trip{{Pattern: 2, Progress: 10500}}
+	if d, ok := distanceBetween(a, b); !ok || 1500 != d {
+		t.Errorf("Expected 1500 feet from trip progress, found %v", d)
+	}
+	if d, _ := distanceBetween(b, a); 1500 != d {
+		t.Errorf("Expected 1500 feet regardless of order, found %v", d)
+	}
+
+	a.BlockPosition.Feet, b.BlockPosition.Feet = 3000, 2200
+	if d, _ := distanceBetween(a, b); 800 != d {
+		t.Errorf("Expected 800 feet from distances to the stop, found %v", d)
+	}
+}
@@ -0,0 +1,99 @@
+// Package headway analyses how often vehicles serve a stop, from the
+// arrivals reported by TriMet.
+//
+// Arrivals are grouped by stop, route and direction.  The headways between
+// consecutive arrivals in each group are compared with the schedule, so
+// that riders can be told how often a route really comes, and where gaps in
+// service are opening up:
+//
+//	for _, a := range headway.Analyze(response) {
+//		fmt.Printf("Route %d every %v\n", a.Route, a.EffectiveHeadway())
+//	}
+//
+// The opposite problem, vehicles bunched together, is reported by
+// DetectBunching from the positions of the vehicles.
+package headway
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// A Key identifies the arrivals of a route in one direction at a stop.
+type Key struct {
+	Location  int
+	Route     int
+	Direction int
+}
+
+// KeyOf returns the key of the group a belongs to.
+func KeyOf(a *trimet.Arrival) Key {
+	return Key{Location: a.Location, Route: a.Route, Direction: a.Direction}
+}
+
+// A Group holds the arrivals sharing a Key.
+type Group struct {
+	Key
+
+	// The arrivals in the group, in order of scheduled time.
+	Arrivals []trimet.Arrival
+}
+
+// GroupArrivals groups arrivals by stop, route and direction.  Groups are
+// ordered by location, route and then direction.
+func GroupArrivals(arrivals []trimet.Arrival) []Group {
+	indexes := make(map[Key]int)
+	var groups []Group
+	for _, a := range arrivals {
+		key := KeyOf(&a)
+		i, ok := indexes[key]
+		if !ok {
+			i = len(groups)
+			indexes[key] = i
+			groups = append(groups, Group{Key: key})
+		}
+		groups[i].Arrivals = append(groups[i].Arrivals, a)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].Key, groups[j].Key
+		switch {
+		case a.Location != b.Location:
+			return a.Location < b.Location
+		case a.Route != b.Route:
+			return a.Route < b.Route
+		}
+		return a.Direction < b.Direction
+	})
+	for _, g := range groups {
+		sort.SliceStable(g.Arrivals, func(i, j int) bool {
+			return scheduledTime(&g.Arrivals[i]).Before(scheduledTime(&g.Arrivals[j]))
+		})
+	}
+	return groups
+}
+
+// scheduledTime returns the scheduled time of a, or the zero time if it
+// has none.
+func scheduledTime(a *trimet.Arrival) time.Time {
+	if nil == a.Scheduled || nil == a.Scheduled.Time {
+		return time.Time{}
+	}
+	return *a.Scheduled.Time
+}
+
+// predictedTime returns the estimated time of a, or its scheduled time if
+// it has not been estimated.
+func predictedTime(a *trimet.Arrival) time.Time {
+	if nil != a.Estimated && nil != a.Estimated.Time {
+		return *a.Estimated.Time
+	}
+	return scheduledTime(a)
+}
+
+// canceled reports whether a will not arrive.
+func canceled(a *trimet.Arrival) bool {
+	return "canceled" == a.Status
+}
@@ -0,0 +1,40 @@
+package trimet
+
+import "time"
+
+// An Itinerary is one way of making a planned trip, made up of walking and
+// transit legs.
+type Itinerary struct {
+	// The itinerary's number within the plan.
+	ID int
+
+	// Time the itinerary begins.
+	Start *Time
+
+	// Time the itinerary ends.
+	End *Time
+
+	// Total time taken by the itinerary.
+	Duration time.Duration
+
+	// Total distance travelled in miles.
+	Distance float64
+
+	// Number of transfers between transit vehicles.
+	Transfers int
+
+	// Time spent walking.
+	WalkingTime time.Duration
+
+	// Time spent aboard transit vehicles.
+	TransitTime time.Duration
+
+	// Time spent waiting for transit vehicles.
+	WaitingTime time.Duration
+
+	// Fares for the itinerary, if any transit is used.
+	Fare *Fare
+
+	// The itinerary's legs, in the order they are travelled.
+	Legs []Leg
+}
@@ -0,0 +1,52 @@
+package trimet
+
+import "time"
+
+// Leg modes reported by the trip planner.
+const (
+	LegWalk         = "Walk"
+	LegBus          = "Bus"
+	LegLightRail    = "Light Rail"
+	LegStreetcar    = "Streetcar"
+	LegCommuterRail = "Commuter Rail"
+	LegAerialTram   = "Aerial Tram"
+)
+
+// A Leg is part of an Itinerary travelled either on foot or aboard a single
+// transit vehicle.
+type Leg struct {
+	// How the leg is travelled, such as LegWalk or LegBus.
+	Mode string
+
+	// Time the leg begins.
+	Start *Time
+
+	// Time the leg ends.
+	End *Time
+
+	// Time taken by the leg.
+	Duration time.Duration
+
+	// Distance travelled in miles.
+	Distance float64
+
+	// Where the leg begins.  For transit legs this is the boarding stop.
+	From *Location
+
+	// Where the leg ends.  For transit legs this is the alighting stop.
+	To *Location
+
+	// The route taken by a transit leg, or nil for walking legs.
+	Route *Route
+
+	// The block serving a transit leg.
+	Block int
+
+	// Step-by-step directions for walking legs.
+	Steps []Step
+}
+
+// IsTransit reports whether the leg is travelled aboard a transit vehicle.
+func (l *Leg) IsTransit() bool {
+	return nil != l.Route
+}
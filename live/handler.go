@@ -0,0 +1,212 @@
+// Package live streams arrivals to browsers over Server-Sent Events or
+// WebSocket, so that real-time boards need neither an AppID nor direct
+// access to TriMet.
+//
+// A Handler serves requests such as
+//
+//	GET /arrivals?locIDs=8989,7787
+//
+// by subscribing to the stops with a trimet.ArrivalsHub, which shares the
+// polling of each stop among every connected client.  The client is sent a
+// snapshot of the arrivals at its stops once they are known, then the
+// changes observed by each later poll.  WebSocket upgrade requests are
+// answered over WebSocket, and all others with an event stream.
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/juniorrobot/gotrimet"
+)
+
+const (
+	// DefaultKeepAlive is the interval at which a Handler sends keep-alives
+	// to idle clients when none is given.
+	DefaultKeepAlive = 15 * time.Second
+
+	// DefaultMaxLocationIDs is the number of location IDs a client may
+	// request when a Handler is given no limit.  The hub splits its polls
+	// into requests for trimet.MaxLocationIDs stops, so each further ten
+	// stops a client adds costs another TriMet request every poll; the
+	// limit keeps one client from using up the AppID's quota.
+	DefaultMaxLocationIDs = 50
+)
+
+// A Handler streams the arrivals at the stops requested by its locIDs
+// parameter.
+type Handler struct {
+	// The hub which polls arrivals on behalf of clients.  It must be run
+	// for clients to receive arrivals.
+	Hub *trimet.ArrivalsHub
+
+	// Maximum number of location IDs a client may request.  Defaults to
+	// DefaultMaxLocationIDs if not positive.
+	MaxLocationIDs int
+
+	// Interval at which idle connections are kept alive.  Defaults to
+	// DefaultKeepAlive if not positive.
+	KeepAlive time.Duration
+
+	// Upgrader upgrades WebSocket requests.  Its CheckOrigin rejects cross
+	// origin requests by default.
+	Upgrader websocket.Upgrader
+
+	// ErrorLog logs the errors of failed polls, which clients are only told
+	// the cause of.  If nil, errors are logged with the log package's
+	// standard logger.
+	ErrorLog *log.Logger
+}
+
+// NewHandler returns a new Handler streaming arrivals polled by hub.
+func NewHandler(hub *trimet.ArrivalsHub) *Handler {
+	return &Handler{Hub: hub}
+}
+
+// A stream sends messages to a connected client.
+type stream interface {
+	send(m *Message) error
+	keepAlive() error
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if "GET" != r.Method {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	locationIDs, err := h.locationIDs(r)
+	if nil != err {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var s stream
+	if websocket.IsWebSocketUpgrade(r) {
+		conn, err := h.Upgrader.Upgrade(w, r, nil)
+		if nil != err {
+			// Upgrade has already responded to the client.
+			return
+		}
+		defer conn.Close()
+		s = newWebSocketStream(conn, cancel)
+	} else {
+		s, err = newEventStream(w)
+		if nil != err {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.serve(ctx, s, locationIDs)
+}
+
+// serve streams the arrivals at locationIDs to s until ctx is done or s
+// fails.
+func (h *Handler) serve(ctx context.Context, s stream, locationIDs []int) {
+	sub := h.Hub.Subscribe(locationIDs...)
+	defer sub.Unsubscribe()
+
+	interval := h.KeepAlive
+	if interval <= 0 {
+		interval = DefaultKeepAlive
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *trimet.ArrivalsResponse
+	for {
+		var err error
+		select {
+		case update, ok := <-sub.Updates:
+			if !ok {
+				return
+			}
+			err = h.sendUpdate(s, last, update)
+			if nil != update.Response {
+				last = update.Response
+			}
+		case <-ticker.C:
+			err = s.keepAlive()
+		case <-ctx.Done():
+			return
+		}
+		if nil != err {
+			return
+		}
+	}
+}
+
+// sendUpdate sends the messages reporting update to s, given the arrivals
+// last sent.
+func (h *Handler) sendUpdate(s stream, last *trimet.ArrivalsResponse, update trimet.ArrivalsUpdate) error {
+	if nil != update.Err {
+		h.logf("live: polling arrivals: %v", update.Err)
+		if err := s.send(newErrorMessage(update.Err)); nil != err {
+			return err
+		}
+	}
+	switch {
+	case nil == update.Response:
+		return nil
+	case nil == last:
+		return s.send(newSnapshotMessage(update.Response))
+	}
+
+	m := newUpdateMessage(update.Response, trimet.DiffArrivals(last, update.Response))
+	if 0 == len(m.Changes) {
+		return nil
+	}
+	return s.send(m)
+}
+
+func (h *Handler) logf(format string, args ...interface{}) {
+	if nil != h.ErrorLog {
+		h.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+// locationIDs parses the location IDs requested by r, which may be given
+// as a comma separated list, repeated, or both.
+func (h *Handler) locationIDs(r *http.Request) ([]int, error) {
+	max := h.MaxLocationIDs
+	if max <= 0 {
+		max = DefaultMaxLocationIDs
+	}
+
+	var ids []int
+	seen := make(map[int]bool)
+	for _, value := range r.URL.Query()["locIDs"] {
+		for _, field := range strings.Split(value, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(field))
+			if nil != err || id <= 0 {
+				return nil, fmt.Errorf("Invalid location ID %q", field)
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	switch {
+	case 0 == len(ids):
+		return nil, errors.New("Missing required argument locIDs")
+	case len(ids) > max:
+		return nil, fmt.Errorf("Too many location IDs (maximum %d)", max)
+	}
+	return ids, nil
+}
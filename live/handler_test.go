@@ -0,0 +1,242 @@
+package live
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/juniorrobot/gotrimet"
+	"github.com/juniorrobot/gotrimet/trimettest"
+)
+
+// newTestHandler serves a Handler polling a fake TriMet server with one
+// arrival at stop 8989.
+func newTestHandler(t *testing.T) (*trimettest.Server, *httptest.Server, func()) {
+	srv := trimettest.NewServer("abc123")
+	srv.AddLocation(trimet.Location{ID: 8989, Description: "NW 23rd & Marshall"})
+	srv.AddArrival(trimet.Arrival{
+		Location:  8989,
+		Route:     15,
+		Block:     1537,
+		Status:    "scheduled",
+		Scheduled: trimet.NewTime(srv.Now().Add(10 * time.Minute)),
+	})
+
+	hub := trimet.NewArrivalsHub(srv.Client().Arrivals, nil, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	go hub.Run(ctx)
+
+	server := httptest.NewServer(NewHandler(hub))
+	return srv, server, func() {
+		server.Close()
+		cancel()
+		srv.Close()
+	}
+}
+
+// addArrival adds a second arrival at stop 8989.
+func addArrival(srv *trimettest.Server) {
+	srv.AddArrival(trimet.Arrival{
+		Location:  8989,
+		Route:     15,
+		Block:     1538,
+		Status:    "scheduled",
+		Scheduled: trimet.NewTime(srv.Now().Add(20 * time.Minute)),
+	})
+}
+
+// readEvent reads the next event from an event stream, skipping comments.
+func readEvent(t *testing.T, r *bufio.Reader) (string, *Message) {
+	var event string
+	m := new(Message)
+	for {
+		line, err := r.ReadString('\n')
+		if nil != err {
+			t.Fatalf("Unexpected error reading event stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), m); nil != err {
+				t.Fatalf("Unable to decode event data %q: %v", line, err)
+			}
+		case "" == line && "" != event:
+			return event, m
+		}
+	}
+}
+
+func checkSnapshot(t *testing.T, m *Message) {
+	if SnapshotMessage != m.Type {
+		t.Fatalf("Expected %v message, found %+v", SnapshotMessage, m)
+	}
+	if 1 != len(m.Locations) || 8989 != m.Locations[0].ID {
+		t.Errorf("Expected snapshot of location 8989, found %+v", m.Locations)
+	}
+	if 1 != len(m.Arrivals) || 1537 != m.Arrivals[0].Block {
+		t.Errorf("Expected snapshot of block 1537, found %+v", m.Arrivals)
+	}
+}
+
+func checkUpdate(t *testing.T, m *Message) {
+	if UpdateMessage != m.Type {
+		t.Fatalf("Expected %v message, found %+v", UpdateMessage, m)
+	}
+	if 1 != len(m.Changes) || "appeared" != m.Changes[0].Type || 1538 != m.Changes[0].Arrival.Block {
+		t.Errorf("Expected block 1538 to appear, found %+v", m.Changes)
+	}
+}
+
+func TestHandler_eventStream(t *testing.T) {
+	srv, server, done := newTestHandler(t)
+	defer done()
+
+	resp, err := http.Get(server.URL + "?locIDs=8989")
+	if nil != err {
+		t.Fatalf("Unexpected error connecting: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); "text/event-stream" != contentType {
+		t.Errorf("Expected Content-Type text/event-stream, found %v", contentType)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	event, m := readEvent(t, r)
+	if SnapshotMessage != event {
+		t.Errorf("Expected %v event, found %v", SnapshotMessage, event)
+	}
+	checkSnapshot(t, m)
+
+	addArrival(srv)
+	event, m = readEvent(t, r)
+	if UpdateMessage != event {
+		t.Errorf("Expected %v event, found %v", UpdateMessage, event)
+	}
+	checkUpdate(t, m)
+}
+
+func TestHandler_webSocket(t *testing.T) {
+	srv, server, done := newTestHandler(t)
+	defer done()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?locIDs=8989"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if nil != err {
+		t.Fatalf("Unexpected error connecting: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	m := new(Message)
+	if err := conn.ReadJSON(m); nil != err {
+		t.Fatalf("Unexpected error reading snapshot: %v", err)
+	}
+	checkSnapshot(t, m)
+
+	addArrival(srv)
+	m = new(Message)
+	if err := conn.ReadJSON(m); nil != err {
+		t.Fatalf("Unexpected error reading update: %v", err)
+	}
+	checkUpdate(t, m)
+}
+
+func TestHandler_badRequest(t *testing.T) {
+	var tooMany []string
+	for id := 1; id <= DefaultMaxLocationIDs+1; id++ {
+		tooMany = append(tooMany, strconv.Itoa(id))
+	}
+
+	handler := NewHandler(nil)
+	for _, query := range []string{"", "?locIDs=", "?locIDs=8989,stop", "?locIDs=-1",
+		"?locIDs=" + strings.Join(tooMany, ",")} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/"+query, nil))
+		if http.StatusBadRequest != w.Code {
+			t.Errorf("Expected %q to respond %v, found %v", query, http.StatusBadRequest, w.Code)
+		}
+	}
+
+	handler.MaxLocationIDs = 2
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?locIDs=1,2,3", nil))
+	if http.StatusBadRequest != w.Code {
+		t.Errorf("Expected more than MaxLocationIDs to respond %v, found %v", http.StatusBadRequest, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/?locIDs=8989", nil))
+	if http.StatusMethodNotAllowed != w.Code {
+		t.Errorf("Expected POST to respond %v, found %v", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestHandler_error(t *testing.T) {
+	srv := trimettest.NewServer("abc123")
+	defer srv.Close()
+	srv.AddLocation(trimet.Location{ID: 8989})
+	srv.Fail("arrivals", trimettest.Failure{Status: http.StatusServiceUnavailable})
+
+	hub := trimet.NewArrivalsHub(srv.Client().Arrivals, nil, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	logged := new(bytes.Buffer)
+	handler := NewHandler(hub)
+	handler.ErrorLog = log.New(logged, "", 0)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?locIDs=8989")
+	if nil != err {
+		t.Fatalf("Unexpected error connecting: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var stream strings.Builder
+	r := bufio.NewReader(io.TeeReader(resp.Body, &stream))
+	event, m := readEvent(t, r)
+	if ErrorMessage != event || "upstream unavailable" != m.Error {
+		t.Errorf("Expected %v event for an unavailable upstream, found %v %+v", ErrorMessage, event, m)
+	}
+	if strings.Contains(stream.String(), "abc123") {
+		t.Errorf("Expected the AppID not to be streamed, found %q", stream.String())
+	}
+	if !strings.Contains(logged.String(), "503") {
+		t.Errorf("Expected the error to be logged, found %q", logged.String())
+	}
+}
+
+func TestErrorText(t *testing.T) {
+	tooMany := &http.Response{StatusCode: http.StatusTooManyRequests}
+	for _, test := range []struct {
+		Err    error
+		Expect string
+	}{
+		{fmt.Errorf("locIDs: %w", trimet.ErrUnknownLocation), "invalid location"},
+		{&trimet.StatusError{Response: tooMany}, "rate limited"},
+		{&trimet.PartialArrivalsError{Failures: []trimet.ArrivalsFailure{{Err: trimet.ErrUnknownLocation}}}, "invalid location"},
+		{trimet.ErrServerUnavailable, "upstream unavailable"},
+		{errors.New("Get http://developer.trimet.org/ws/V1/arrivals?appID=abc123"), "upstream unavailable"},
+	} {
+		if found := errorText(test.Err); test.Expect != found {
+			t.Errorf("Expected %q for %v, found %q", test.Expect, test.Err, found)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package live
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// Types of Message.
+const (
+	// SnapshotMessage holds every arrival at the client's stops.  It is
+	// the first message sent once the stops have been polled.
+	SnapshotMessage = "snapshot"
+
+	// UpdateMessage holds the changes observed by a poll since the
+	// previous message.
+	UpdateMessage = "update"
+
+	// ErrorMessage reports a failed poll.  Updates continue with the next
+	// successful poll.
+	ErrorMessage = "error"
+)
+
+// A Message is sent to clients as JSON: the data of an event stream event
+// named by its Type, or a WebSocket text message.
+type Message struct {
+	Type string `json:"type"`
+
+	// The time of the oldest poll the message reflects.
+	QueryTime *trimet.Time `json:"queryTime,omitempty"`
+
+	// The stops and their arrivals, in a snapshot.
+	Locations []trimet.Location `json:"locations,omitempty"`
+	Arrivals  []trimet.Arrival  `json:"arrivals,omitempty"`
+
+	// The changed arrivals, in an update.
+	Changes []Change `json:"changes,omitempty"`
+
+	// The cause of a failed poll: "invalid location", "rate limited" or
+	// "upstream unavailable".
+	Error string `json:"error,omitempty"`
+}
+
+// A Change reports how an arrival changed.
+type Change struct {
+	// One of "appeared", "eta changed", "status changed", "vehicle
+	// departed", "departed" or "dropped", as described by
+	// trimet.ArrivalEventType.
+	Type string `json:"type"`
+
+	// The arrival as now reported, or as last reported if it departed or
+	// was dropped.
+	Arrival *trimet.Arrival `json:"arrival"`
+}
+
+func newSnapshotMessage(response *trimet.ArrivalsResponse) *Message {
+	return &Message{
+		Type:      SnapshotMessage,
+		QueryTime: response.QueryTime,
+		Locations: response.Locations,
+		Arrivals:  response.Arrivals,
+	}
+}
+
+func newUpdateMessage(response *trimet.ArrivalsResponse, events []trimet.ArrivalEvent) *Message {
+	m := &Message{Type: UpdateMessage, QueryTime: response.QueryTime}
+	for _, e := range events {
+		m.Changes = append(m.Changes, Change{Type: e.Type.String(), Arrival: e.Arrival})
+	}
+	return m
+}
+
+func newErrorMessage(err error) *Message {
+	return &Message{Type: ErrorMessage, Error: errorText(err)}
+}
+
+// errorText returns the cause of err reported to clients.  The error itself
+// is never sent, as it may hold the URL of the request and so the AppID.
+func errorText(err error) string {
+	var statusErr *trimet.StatusError
+	switch {
+	case errors.Is(err, trimet.ErrUnknownLocation):
+		return "invalid location"
+	case errors.As(err, &statusErr) && http.StatusTooManyRequests == statusErr.Response.StatusCode:
+		return "rate limited"
+	}
+	return "upstream unavailable"
+}
@@ -0,0 +1,51 @@
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// An eventStream sends messages as Server-Sent Events.
+//
+// Reference: https://html.spec.whatwg.org/multipage/server-sent-events.html
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newEventStream starts an event stream response on w.
+func newEventStream(w http.ResponseWriter) (*eventStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("Streaming is not supported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &eventStream{w: w, flusher: flusher}, nil
+}
+
+func (s *eventStream) send(m *Message) error {
+	data, err := json.Marshal(m)
+	if nil != err {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", m.Type, data); nil != err {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// keepAlive sends a comment, which clients ignore.
+func (s *eventStream) keepAlive() error {
+	if _, err := fmt.Fprint(s.w, ": keep-alive\n\n"); nil != err {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,38 @@
+package live
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeWait is the time allowed to write a message to a WebSocket client.
+const writeWait = 10 * time.Second
+
+// A webSocketStream sends messages as WebSocket text messages.
+type webSocketStream struct {
+	conn *websocket.Conn
+}
+
+// newWebSocketStream returns a stream sending on conn.  Messages from the
+// client are discarded, and cancel is called once the connection closes.
+func newWebSocketStream(conn *websocket.Conn, cancel func()) *webSocketStream {
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); nil != err {
+				return
+			}
+		}
+	}()
+	return &webSocketStream{conn: conn}
+}
+
+func (s *webSocketStream) send(m *Message) error {
+	s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return s.conn.WriteJSON(m)
+}
+
+func (s *webSocketStream) keepAlive() error {
+	return s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+}
@@ -6,25 +6,25 @@ package trimet
 // describe the stop requested, and others to describe the location of any
 // layovers.
 type Location struct {
-	ID int `json:"locid"`
+	ID int `json:"locid" xml:"locid,attr"`
 
 	// The public location description of the stop.
-	Description string `json:"desc"`
+	Description string `json:"desc" xml:"desc,attr"`
 
 	// The direction of traffic at the stop.
-	Direction string `json:"dir"`
+	Direction string `json:"dir" xml:"dir,attr"`
 
 	// The latitude of the stop.
-	Lat float64 `json:"lat"`
+	Lat float64 `json:"lat" xml:"lat,attr"`
 	// The longitude of the stop.
-	Lon float64 `json:"lng"`
+	Lon float64 `json:"lng" xml:"lng,attr"`
 
 	// The stop's sequence number in a Route's Direction.
-	Sequence int `json:"seq"`
+	Sequence int `json:"seq" xml:"seq,attr"`
 
 	// Whether the stop is considered a time point for a Route's Direction.
-	TimePoint bool `json:"tp"`
+	TimePoint bool `json:"tp" xml:"tp,attr"`
 
 	// List of routes that service the stop.
-	Routes []Route `json:"route"`
+	Routes []Route `json:"route" xml:"route"`
 }
@@ -0,0 +1,103 @@
+package trimet
+
+import (
+	"net/http"
+	"time"
+)
+
+// An Interceptor observes and may modify the traffic of a Client, for
+// purposes such as logging, metrics, header injection or body capture.
+//
+// Interceptors are applied to requests in the order they appear in
+// Client.Interceptors, and to responses in the reverse order.
+type Interceptor interface {
+	// InterceptRequest is called by NewRequest with the request it built.
+	// The request may be modified.  Returning an error aborts the request.
+	InterceptRequest(req *http.Request) error
+
+	// InterceptResponse is called after each attempt to send a request,
+	// including failed attempts and responses served from the Cache.  The
+	// exchange's Err may be replaced to change the error returned.
+	InterceptResponse(ex *Exchange)
+}
+
+// An Exchange describes a single attempt at an API request.
+type Exchange struct {
+	// The request sent.
+	Request *http.Request
+
+	// The HTTP response, or nil if none was received.  Its body has already
+	// been read and closed.
+	Response *http.Response
+
+	// The raw response body.
+	Body []byte
+
+	// The value the response body was decoded into, if any.
+	Result interface{}
+
+	// The error resulting from the attempt, if any.
+	Err error
+
+	// The attempt number, starting at 1, or 0 for cached responses.
+	Attempt int
+
+	// Whether the response was served from the Cache.
+	Cached bool
+
+	// When the attempt began and how long it took.
+	Start    time.Time
+	Duration time.Duration
+}
+
+// InterceptorFuncs adapts functions to an Interceptor.  Either function may be
+// nil.
+type InterceptorFuncs struct {
+	Request  func(req *http.Request) error
+	Response func(ex *Exchange)
+}
+
+// InterceptRequest implements Interceptor.
+func (f InterceptorFuncs) InterceptRequest(req *http.Request) error {
+	if nil == f.Request {
+		return nil
+	}
+	return f.Request(req)
+}
+
+// InterceptResponse implements Interceptor.
+func (f InterceptorFuncs) InterceptResponse(ex *Exchange) {
+	if nil != f.Response {
+		f.Response(ex)
+	}
+}
+
+// HeaderInterceptor returns an Interceptor which sets header on each request.
+func HeaderInterceptor(header http.Header) Interceptor {
+	return InterceptorFuncs{
+		Request: func(req *http.Request) error {
+			for k, vals := range header {
+				req.Header.Del(k)
+				for _, v := range vals {
+					req.Header.Add(k, v)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func (c *Client) interceptRequest(req *http.Request) error {
+	for _, i := range c.Interceptors {
+		if err := i.InterceptRequest(req); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) interceptResponse(ex *Exchange) {
+	for i := len(c.Interceptors) - 1; i >= 0; i-- {
+		c.Interceptors[i].InterceptResponse(ex)
+	}
+}
@@ -0,0 +1,97 @@
+package trimet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Interceptors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "X-Team", "boards")
+		fmt.Fprint(w, `{"resultSet":{"arrival":[{"locid":8989}]}}`)
+	})
+
+	var calls []string
+	var exchange *Exchange
+	trace := func(name string) Interceptor {
+		return InterceptorFuncs{
+			Request: func(req *http.Request) error {
+				calls = append(calls, name+" request")
+				return nil
+			},
+			Response: func(ex *Exchange) {
+				calls = append(calls, name+" response")
+				exchange = ex
+			},
+		}
+	}
+
+	client.Interceptors = []Interceptor{
+		trace("outer"),
+		HeaderInterceptor(http.Header{"X-Team": {"boards"}}),
+		trace("inner"),
+	}
+
+	arrivals, err := client.Arrivals.Get(&ArrivalsRequest{LocationIDs: []int{8989}})
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error: %v", err)
+	}
+
+	expect := []string{"outer request", "inner request", "inner response", "outer response"}
+	if !reflect.DeepEqual(expect, calls) {
+		t.Errorf("Expected interceptor calls %v, found %v", expect, calls)
+	}
+
+	if nil == exchange || nil == exchange.Response || 0 == len(exchange.Body) {
+		t.Fatalf("Expected exchange with response and body, found %+v", exchange)
+	}
+	if 1 != exchange.Attempt {
+		t.Errorf("Expected attempt 1, found %v", exchange.Attempt)
+	}
+	results, ok := exchange.Result.(*arrivalsResponseResults)
+	if !ok || results.Results != arrivals {
+		t.Errorf("Expected decoded result in exchange, found %#v", exchange.Result)
+	}
+}
+
+func TestClient_Interceptors_requestError(t *testing.T) {
+	c := NewClient(testAppID, nil)
+	abort := errors.New("abort")
+	c.Interceptors = []Interceptor{InterceptorFuncs{
+		Request: func(req *http.Request) error { return abort },
+	}}
+
+	if _, err := c.NewRequest("GET", "arrivals", nil); abort != err {
+		t.Errorf("Expected interceptor error, found %v", err)
+	}
+}
+
+func TestClient_Interceptors_replaceError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	})
+
+	var seen error
+	client.Interceptors = []Interceptor{InterceptorFuncs{
+		Response: func(ex *Exchange) {
+			seen, ex.Err = ex.Err, nil
+		},
+	}}
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	if _, err := client.Do(req, nil); nil != err {
+		t.Errorf("Expected interceptor to clear error, found %v", err)
+	}
+	if !errors.Is(seen, ErrServerUnavailable) {
+		t.Errorf("Expected interceptor to see ErrServerUnavailable, found %v", seen)
+	}
+}
@@ -0,0 +1,298 @@
+package trimet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults used by a PollScheduler whose settings are not positive.
+const (
+	DefaultMinPollInterval = 15 * time.Second
+	DefaultMaxPollInterval = 10 * time.Minute
+	DefaultStaleAfter      = 2 * time.Minute
+	DefaultBudgetWindow    = time.Hour
+)
+
+// A PollScheduler decides when to next poll arrivals at each location, based
+// on how soon the next vehicle is due.
+//
+// The interval for a location is a quarter of the time until its soonest
+// arrival, so that a stop is refreshed a few times as a vehicle approaches,
+// clamped to MinInterval and MaxInterval:
+//
+//   - Arrivals which are only scheduled change rarely, so the interval is
+//     doubled when the soonest arrival has not been estimated.
+//   - A vehicle position older than StaleAfter may jump when the vehicle next
+//     reports, so the interval is halved when the soonest estimated
+//     arrival's position is stale.
+//   - Locations without arrivals are polled every MaxInterval.
+//
+// Intervals are measured from the response's QueryTime, so a response served
+// from a cache is refreshed sooner.
+//
+// A PollScheduler must be created with NewPollScheduler, and is safe for
+// concurrent use.
+type PollScheduler struct {
+	// Bounds of the interval between polls of a location.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+
+	// Age after which a vehicle position is considered stale.
+	StaleAfter time.Duration
+
+	// Maximum number of requests sent by Run in any BudgetWindow.  Zero
+	// means no limit.
+	Budget       int
+	BudgetWindow time.Duration
+
+	mu   sync.Mutex
+	next map[int]time.Time
+	sent []time.Time
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewPollScheduler returns a PollScheduler polling each location between
+// every min and every max.
+func NewPollScheduler(min, max time.Duration) *PollScheduler {
+	return &PollScheduler{
+		MinInterval: min,
+		MaxInterval: max,
+		next:        make(map[int]time.Time),
+		now:         time.Now,
+	}
+}
+
+func (p *PollScheduler) bounds() (time.Duration, time.Duration) {
+	min, max := p.MinInterval, p.MaxInterval
+	if min <= 0 {
+		min = DefaultMinPollInterval
+	}
+	if max <= 0 {
+		max = DefaultMaxPollInterval
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+// Interval returns how long after the response's QueryTime the arrivals at
+// locationID should next be polled.
+func (p *PollScheduler) Interval(locationID int, response *ArrivalsResponse) time.Duration {
+	min, max := p.bounds()
+	if nil == response {
+		return min
+	}
+
+	now := p.now()
+	if nil != response.QueryTime && nil != response.QueryTime.Time {
+		now = *response.QueryTime.Time
+	}
+
+	var soonest *Arrival
+	var soonestAt time.Time
+	for i := range response.Arrivals {
+		a := &response.Arrivals[i]
+		if locationID != a.Location || "canceled" == a.Status {
+			continue
+		}
+		at := expectedTime(a)
+		if at.IsZero() || at.Before(now) {
+			continue
+		}
+		if nil == soonest || at.Before(soonestAt) {
+			soonest, soonestAt = a, at
+		}
+	}
+	if nil == soonest {
+		return max
+	}
+
+	interval := soonestAt.Sub(now) / 4
+	switch {
+	case nil == soonest.Estimated || nil == soonest.Estimated.Time:
+		interval *= 2
+	case p.stale(soonest.BlockPosition.At, now):
+		interval /= 2
+	}
+
+	if interval < min {
+		return min
+	}
+	if interval > max {
+		return max
+	}
+	return interval
+}
+
+// stale reports whether a position reported at is stale at now.
+func (p *PollScheduler) stale(at *Time, now time.Time) bool {
+	if nil == at || nil == at.Time {
+		return false
+	}
+	staleAfter := p.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = DefaultStaleAfter
+	}
+	return now.Sub(*at.Time) > staleAfter
+}
+
+// Schedule records the response to a poll of locationID, returning the time
+// it should next be polled.  A nil response, for a failed poll, schedules the
+// location after MinInterval.
+func (p *PollScheduler) Schedule(locationID int, response *ArrivalsResponse) time.Time {
+	now := p.now()
+	from := now
+	if nil != response && nil != response.QueryTime && nil != response.QueryTime.Time {
+		from = *response.QueryTime.Time
+	}
+	next := from.Add(p.Interval(locationID, response))
+	if next.Before(now) {
+		next = now
+	}
+
+	p.mu.Lock()
+	p.next[locationID] = next
+	p.mu.Unlock()
+	return next
+}
+
+// Next returns when locationID should next be polled.  Locations never
+// scheduled are due immediately.
+func (p *PollScheduler) Next(locationID int) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.next[locationID]
+}
+
+// due returns the locations due by now, earliest first, and the time the
+// next of the others is due.
+func (p *PollScheduler) due(locationIDs []int, now time.Time) ([]int, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var due []int
+	var wake time.Time
+	for _, id := range locationIDs {
+		next := p.next[id]
+		if !next.After(now) {
+			due = append(due, id)
+		} else if wake.IsZero() || next.Before(wake) {
+			wake = next
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return p.next[due[i]].Before(p.next[due[j]])
+	})
+	return due, wake
+}
+
+// reserve takes up to n requests from the budget at now, returning how many
+// were taken, or when the next will be available if none were.
+func (p *PollScheduler) reserve(n int, now time.Time) (int, time.Time) {
+	if p.Budget <= 0 {
+		return n, time.Time{}
+	}
+	window := p.BudgetWindow
+	if window <= 0 {
+		window = DefaultBudgetWindow
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cutoff := now.Add(-window)
+	for 0 < len(p.sent) && !p.sent[0].After(cutoff) {
+		p.sent = p.sent[1:]
+	}
+	if available := p.Budget - len(p.sent); available < n {
+		n = available
+	}
+	if n <= 0 {
+		return 0, p.sent[0].Add(window)
+	}
+	for i := 0; i < n; i++ {
+		p.sent = append(p.sent, now)
+	}
+	return n, time.Time{}
+}
+
+// Run polls arrivals at locationIDs using s whenever they are due, until ctx
+// is done, passing each response to handle along with any error, just as
+// ArrivalsService.GetContext returns them.  Other request options are taken
+// from r, which may be nil.
+//
+// Locations due at the same time are polled together, in requests of up to
+// MaxLocationIDs locations.  When the Budget is exhausted, the locations due
+// soonest are polled first and the rest wait for the budget to recover.
+//
+// Run returns the error of ctx once it is done.
+func (p *PollScheduler) Run(ctx context.Context, s *ArrivalsService, r *ArrivalsRequest, locationIDs []int, handle func(*ArrivalsResponse, error)) error {
+	if 0 == len(locationIDs) {
+		return errors.New("Missing location IDs to poll")
+	}
+
+	for {
+		now := p.now()
+		due, wake := p.due(locationIDs, now)
+		if 0 != len(due) {
+			requests := (len(due) + MaxLocationIDs - 1) / MaxLocationIDs
+			n, available := p.reserve(requests, now)
+			if 0 < n {
+				if limit := n * MaxLocationIDs; limit < len(due) {
+					due = due[:limit]
+				}
+				p.poll(ctx, s, r, due, handle)
+				continue
+			}
+			if wake.IsZero() || available.Before(wake) {
+				wake = available
+			}
+		}
+
+		if err := sleepContext(ctx, wake.Sub(now)); nil != err {
+			return err
+		}
+	}
+}
+
+// poll requests arrivals at locationIDs and schedules their next polls.
+func (p *PollScheduler) poll(ctx context.Context, s *ArrivalsService, r *ArrivalsRequest, locationIDs []int, handle func(*ArrivalsResponse, error)) {
+	req := new(ArrivalsRequest)
+	if nil != r {
+		*req = *r
+	}
+	req.LocationIDs = locationIDs
+
+	response, err := s.GetContext(ctx, req)
+	if nil != ctx.Err() {
+		return
+	}
+
+	failed := make(map[int]bool)
+	var partial *PartialArrivalsError
+	switch {
+	case errors.As(err, &partial):
+		for _, id := range partial.FailedLocationIDs() {
+			failed[id] = true
+		}
+	case nil != err:
+		for _, id := range locationIDs {
+			failed[id] = true
+		}
+	}
+	for _, id := range locationIDs {
+		if failed[id] {
+			p.Schedule(id, nil)
+		} else {
+			p.Schedule(id, response)
+		}
+	}
+
+	handle(response, err)
+}
@@ -0,0 +1,110 @@
+package trimet
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPollScheduler_Interval(t *testing.T) {
+	now := time.Date(2014, 1, 12, 17, 12, 0, 0, time.UTC)
+	p := NewPollScheduler(15*time.Second, 10*time.Minute)
+	p.now = func() time.Time { return now }
+
+	at := func(d time.Duration) *Time {
+		return NewTime(now.Add(d))
+	}
+	response := func(arrivals ...Arrival) *ArrivalsResponse {
+		return &ArrivalsResponse{Response: Response{QueryTime: NewTime(now)}, Arrivals: arrivals}
+	}
+	fresh := Position{At: at(-30 * time.Second)}
+	stale := Position{At: at(-5 * time.Minute)}
+
+	tests := []struct {
+		name     string
+		response *ArrivalsResponse
+		expect   time.Duration
+	}{
+		{"failed", nil, 15 * time.Second},
+		{"no arrivals", response(), 10 * time.Minute},
+		{"other location", response(Arrival{Location: 1, Estimated: at(time.Minute)}), 10 * time.Minute},
+		{"estimated", response(
+			Arrival{Location: 8989, Status: "estimated", Estimated: at(8 * time.Minute), BlockPosition: fresh},
+			Arrival{Location: 8989, Status: "estimated", Estimated: at(4 * time.Minute), BlockPosition: fresh},
+		), time.Minute},
+		{"scheduled", response(Arrival{Location: 8989, Status: "scheduled", Scheduled: at(4 * time.Minute)}), 2 * time.Minute},
+		{"stale", response(Arrival{Location: 8989, Status: "estimated", Estimated: at(4 * time.Minute), BlockPosition: stale}), 30 * time.Second},
+		{"imminent", response(Arrival{Location: 8989, Status: "estimated", Estimated: at(20 * time.Second)}), 15 * time.Second},
+		{"distant", response(Arrival{Location: 8989, Status: "scheduled", Scheduled: at(3 * time.Hour)}), 10 * time.Minute},
+		{"canceled", response(Arrival{Location: 8989, Status: "canceled", Scheduled: at(time.Minute)}), 10 * time.Minute},
+		{"passed", response(Arrival{Location: 8989, Status: "estimated", Estimated: at(-time.Minute)}), 10 * time.Minute},
+	}
+	for _, test := range tests {
+		if found := p.Interval(8989, test.response); test.expect != found {
+			t.Errorf("Expected %v interval of %v, found %v", test.name, test.expect, found)
+		}
+	}
+}
+
+func TestPollScheduler_Schedule_cached(t *testing.T) {
+	now := time.Date(2014, 1, 12, 17, 12, 0, 0, time.UTC)
+	p := NewPollScheduler(15*time.Second, 10*time.Minute)
+	p.now = func() time.Time { return now }
+
+	// The vehicle was 4m10s away when the cached response was made, so it is
+	// refreshed 62.5s after its query time.
+	response := &ArrivalsResponse{
+		Response: Response{QueryTime: NewTime(now.Add(-10 * time.Second))},
+		Arrivals: []Arrival{{Location: 8989, Status: "estimated", Estimated: NewTime(now.Add(4 * time.Minute))}},
+	}
+	next := p.Schedule(8989, response)
+	if expect := now.Add(52*time.Second + 500*time.Millisecond); !expect.Equal(next) || !next.Equal(p.Next(8989)) {
+		t.Errorf("Expected next poll at %v, found %v", expect, next)
+	}
+}
+
+func TestPollScheduler_Run_budget(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	var requests []string
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.FormValue("locIDs"))
+		mu.Unlock()
+		w.Write([]byte(`{"resultSet":{"queryTime":"2014-01-12T17:12:09.351-0800"}}`))
+	})
+
+	p := NewPollScheduler(time.Hour, time.Hour)
+	p.Budget = 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	handled := 0
+	err := p.Run(ctx, client.Arrivals, nil, locationIDRange(1, 12), func(response *ArrivalsResponse, err error) {
+		if nil != err {
+			t.Errorf("Unexpected error polling: %v", err)
+		}
+		handled++
+	})
+	if context.DeadlineExceeded != err {
+		t.Errorf("Expected Run to stop with the context, found %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if 1 != len(requests) || 1 != handled {
+		t.Fatalf("Expected a single request within budget, found %v", requests)
+	}
+	if ids := strings.Split(requests[0], ","); MaxLocationIDs != len(ids) {
+		t.Errorf("Expected %d locations in request, found %v", MaxLocationIDs, ids)
+	}
+	if !p.Next(11).IsZero() || p.Next(1).IsZero() {
+		t.Errorf("Expected only polled locations to be scheduled")
+	}
+}
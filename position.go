@@ -4,31 +4,31 @@ type Distance float64
 
 type Position struct {
 	// The time this position was reported.
-	At *Time `json:"at"`
+	At *Time `json:"at" xml:"at,attr"`
 
 	// Number of feet the vehicle is away from the stop at the time the
 	// position was reported.
-	Feet Distance `json:"feet"`
+	Feet Distance `json:"feet" xml:"feet,attr"`
 
 	// The heading of the vehicle at the time of the position was reported.
-	Heading int `json:"heading"`
+	Heading int `json:"heading" xml:"heading,attr"`
 
 	// The latitude of the vehicle at the time the position was reported.
-	Lat float64 `json:"lat"`
+	Lat float64 `json:"lat" xml:"lat,attr"`
 
 	// The longitude of the vehicle at the time the position was reported.
-	Lon float64 `json:"lng"`
+	Lon float64 `json:"lng" xml:"lng,attr"`
 
 	// Occurs for every trip the vehicle must traverse to arrive at a stop.
-	Trips []Trip `json:"trip"`
+	Trips []Trip `json:"trip" xml:"trip"`
 
 	// Occurs for every layover the vehicle has between its position and the
 	// requested arrival.
 	Layover struct {
 		// The time the layover begins.
-		Start *Time `json:"start"`
+		Start *Time `json:"start" xml:"start,attr"`
 
 		// The time the layover ends.
-		End *Time `json:"end"`
-	} `json:"layover"`
+		End *Time `json:"end" xml:"end,attr"`
+	} `json:"layover" xml:"layover"`
 }
@@ -0,0 +1,83 @@
+package trimet
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// A RateLimiter throttles requests made by a Client.
+type RateLimiter interface {
+	// Wait blocks until a request may be sent or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a RateLimiter that allows bursts of up to Burst requests
+// and refills at Rate requests per second.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewTokenBucket returns a full TokenBucket that refills at rate requests per
+// second up to burst requests.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait implements RateLimiter.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay, err := b.reserve()
+		if nil != err || 0 == delay {
+			return err
+		}
+		if err := sleepContext(ctx, delay); nil != err {
+			return err
+		}
+	}
+}
+
+// reserve takes a token if one is available, or returns how long to wait
+// until one will be.
+func (b *TokenBucket) reserve() (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate <= 0 {
+		return 0, errors.New("TokenBucket rate must be positive")
+	}
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, nil
+	}
+
+	wait := (1 - b.tokens) / b.rate
+	return time.Duration(wait * float64(time.Second)), nil
+}
@@ -0,0 +1,73 @@
+package trimet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_reserve(t *testing.T) {
+	now := time.Date(2014, 1, 12, 17, 0, 0, 0, time.UTC)
+	b := NewTokenBucket(2, 2)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if delay, _ := b.reserve(); 0 != delay {
+			t.Fatalf("Expected burst request %v to proceed, found delay %v", i, delay)
+		}
+	}
+
+	if delay, _ := b.reserve(); 500*time.Millisecond != delay {
+		t.Errorf("Expected empty bucket delay = 500ms, found %v", delay)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if delay, _ := b.reserve(); 0 != delay {
+		t.Errorf("Expected refilled bucket to proceed, found delay %v", delay)
+	}
+}
+
+func TestTokenBucket_badRate(t *testing.T) {
+	b := NewTokenBucket(0, 1)
+	if err := b.Wait(context.Background()); nil == err {
+		t.Error("Expected error for non-positive rate")
+	}
+}
+
+func TestTokenBucket_Wait_canceled(t *testing.T) {
+	b := NewTokenBucket(0.001, 1)
+	b.Wait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := b.Wait(ctx); context.Canceled != err {
+		t.Errorf("Expected context.Canceled, found %v", err)
+	}
+}
+
+func TestDo_rateLimited(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	client.RateLimiter = NewTokenBucket(0.001, 1)
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	if _, err := client.Do(req, nil); nil != err {
+		t.Fatalf("Unexpected error for first request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, _ = client.NewRequestContext(ctx, "GET", "/", nil)
+	if _, err := client.Do(req, nil); context.DeadlineExceeded != err {
+		t.Errorf("Expected throttled request to exceed deadline, found %v", err)
+	}
+}
@@ -11,11 +11,26 @@ type Request struct {
 	// If present returns the JSON result in a JSONP callback function. Only
 	// used if JSON is set to true.
 	Callback string `url:"callback,omitempty"`
+
+	// If true results will be requested in TriMet's default XML format, even
+	// if JSON is also set.  JSON is requested otherwise, and always from
+	// version 2 services.
+	XML bool `url:"-"`
+}
+
+// wantsXML reports whether r requests XML results.
+func (r *Request) wantsXML() bool {
+	return r.XML
+}
+
+// xmlRequester is implemented by request types which embed Request.
+type xmlRequester interface {
+	wantsXML() bool
 }
 
 func newRequest(appID string) *Request {
 	return &Request{
 		AppID: appID,
-		JSON: true,
+		JSON:  true,
 	}
 }
@@ -10,7 +10,7 @@ import (
 // This wraps the standard http.Response returned from TriMet and provides
 // convenient access to things like query times.
 type Response struct {
-	QueryTime *Time `json:"queryTime"`
+	QueryTime *Time `json:"queryTime" xml:"queryTime,attr"`
 }
 
 // An ErrorResponse reports one or more errors caused by an API request.
@@ -20,8 +20,20 @@ type ErrorResponse struct {
 	http *http.Response
 
 	Message struct {
-		Content string `json:"content"`
-	} `json:"errorMessage"`
+		Content string `json:"content" xml:",chardata"`
+	} `json:"errorMessage" xml:"errorMessage"`
+}
+
+// errorResponseResults holds an ErrorResponse nested within a result set, as
+// returned by most TriMet services.
+type errorResponseResults struct {
+	Results *ErrorResponse `json:"resultSet,omitempty"`
+}
+
+// plannerErrorResults holds the error message of a failed trip plan, which
+// the trip planner reports in its own format.
+type plannerErrorResults struct {
+	Message string `xml:"error>message"`
 }
 
 // newErrorResponse creates a new ErrorResponse for the provided http.Response.
@@ -36,3 +48,15 @@ func (r *ErrorResponse) Error() string {
 		r.http.Request.Method, r.http.Request.URL,
 		r.http.StatusCode, r.Message)
 }
+
+// Unwrap returns the class of error described by the TriMet error message,
+// falling back to the HTTP status when the message is not recognized.
+func (r *ErrorResponse) Unwrap() error {
+	if err := classifyMessage(r.Message.Content); nil != err {
+		return err
+	}
+	if nil != r.http {
+		return classifyStatus(r.http.StatusCode)
+	}
+	return nil
+}
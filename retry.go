@@ -0,0 +1,171 @@
+package trimet
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy decides whether a failed API request should be retried.
+type RetryPolicy interface {
+	// Retry is called after the given attempt (starting at 1) fails with err.
+	// The response is nil if no HTTP response was received.  It returns how
+	// long to wait before the next attempt, and whether to make one at all.
+	Retry(attempt int, response *http.Response, err error) (time.Duration, bool)
+}
+
+// BackoffPolicy is a RetryPolicy that retries with exponential backoff.
+//
+// The delay before the nth retry is BaseDelay * 2^(n-1), capped at MaxDelay
+// and randomized by Jitter.  If the response carries a Retry-After header,
+// that delay is used instead, still capped at MaxDelay.
+type BackoffPolicy struct {
+	// Total number of attempts, including the first.  Values less than 2
+	// disable retries.
+	MaxAttempts int
+
+	// Delay before the first retry.
+	BaseDelay time.Duration
+
+	// Upper bound on any single delay.  Zero means no bound.
+	MaxDelay time.Duration
+
+	// Fraction of each delay, in the range [0,1], that is randomized.  A
+	// Jitter of 0.5 yields delays between 50% and 100% of the computed
+	// backoff.
+	Jitter float64
+
+	// Reports whether an error may be retried.  Defaults to IsRetryable.
+	Retryable func(error) bool
+}
+
+// NewBackoffPolicy returns a BackoffPolicy with reasonable defaults for the
+// TriMet web services.
+func NewBackoffPolicy() *BackoffPolicy {
+	return &BackoffPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// Retry implements RetryPolicy.
+func (p *BackoffPolicy) Retry(attempt int, response *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	retryable := p.Retryable
+	if nil == retryable {
+		retryable = IsRetryable
+	}
+	if !retryable(err) {
+		return 0, false
+	}
+
+	if delay, ok := retryAfter(response); ok {
+		return p.cap(delay), true
+	}
+
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay < p.BaseDelay {
+		// overflow
+		delay = p.MaxDelay
+	}
+	delay = p.cap(delay)
+
+	if jitter := p.Jitter; jitter > 0 {
+		if jitter > 1 {
+			jitter = 1
+		}
+		spread := float64(delay) * jitter
+		delay = time.Duration(float64(delay) - spread*rand.Float64())
+	}
+	return delay, true
+}
+
+func (p *BackoffPolicy) cap(delay time.Duration) time.Duration {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// IsRetryable reports whether err is likely to be transient.
+//
+// Server unavailability and network timeouts are retryable.  Context errors
+// and errors caused by the request itself are not.
+func IsRetryable(err error) bool {
+	if nil == err {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrServerUnavailable) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) &&
+		http.StatusTooManyRequests == statusErr.Response.StatusCode {
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of response, which may be given
+// either in seconds or as an HTTP date.
+func retryAfter(response *http.Response) (time.Duration, bool) {
+	if nil == response {
+		return 0, false
+	}
+
+	value := response.Header.Get("Retry-After")
+	if "" == value {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); nil == err {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); nil == err {
+		delay := time.Until(at)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d to elapse or ctx to be done, whichever is first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
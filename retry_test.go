@@ -0,0 +1,181 @@
+package trimet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDo_retry(t *testing.T) {
+	setup()
+	defer teardown()
+
+	attempts := 0
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"resultSet":{"queryTime":"2014-01-12T17:12:09.351-0800"}}`)
+	})
+
+	client.RetryPolicy = &BackoffPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	_, err := client.Arrivals.Get(&ArrivalsRequest{LocationIDs: []int{8989}})
+	if nil != err {
+		t.Fatalf("Unexpected error after retries: %v", err)
+	}
+	if 3 != attempts {
+		t.Errorf("Expected 3 attempts, found %v", attempts)
+	}
+}
+
+func TestDo_retryExhausted(t *testing.T) {
+	setup()
+	defer teardown()
+
+	attempts := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+	})
+
+	client.RetryPolicy = &BackoffPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.Do(req, nil)
+	if !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("Expected ErrServerUnavailable, found %v", err)
+	}
+	if 2 != attempts {
+		t.Errorf("Expected 2 attempts, found %v", attempts)
+	}
+}
+
+func TestDo_retryNotRetryable(t *testing.T) {
+	setup()
+	defer teardown()
+
+	attempts := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		fmt.Fprint(w, `{"errorMessage":{"content":"Invalid appID"}}`)
+	})
+
+	client.RetryPolicy = &BackoffPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond}
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	if _, err := client.Do(req, nil); !errors.Is(err, ErrInvalidAppID) {
+		t.Errorf("Expected ErrInvalidAppID, found %v", err)
+	}
+	if 1 != attempts {
+		t.Errorf("Expected 1 attempt, found %v", attempts)
+	}
+}
+
+func TestDo_retryCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	})
+
+	client.RetryPolicy = &BackoffPolicy{MaxAttempts: 5, BaseDelay: time.Hour}
+
+	req, _ := client.NewRequestContext(ctx, "GET", "/", nil)
+	if _, err := client.Do(req, nil); nil == err {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestBackoffPolicy_Retry(t *testing.T) {
+	p := &BackoffPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    300 * time.Millisecond,
+	}
+
+	expect := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+		300 * time.Millisecond,
+	}
+	for i, e := range expect {
+		delay, retry := p.Retry(i+1, nil, ErrServerUnavailable)
+		if !retry {
+			t.Fatalf("Expected attempt %v to be retried", i+1)
+		}
+		if e != delay {
+			t.Errorf("Expected attempt %v delay = %v, found %v", i+1, e, delay)
+		}
+	}
+
+	if _, retry := p.Retry(5, nil, ErrServerUnavailable); retry {
+		t.Error("Expected no retry after MaxAttempts")
+	}
+}
+
+func TestBackoffPolicy_Retry_jitter(t *testing.T) {
+	p := &BackoffPolicy{MaxAttempts: 2, BaseDelay: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		delay, _ := p.Retry(1, nil, ErrServerUnavailable)
+		if delay < 500*time.Millisecond || delay > time.Second {
+			t.Fatalf("Expected jittered delay within [500ms,1s], found %v", delay)
+		}
+	}
+}
+
+func TestBackoffPolicy_Retry_retryAfter(t *testing.T) {
+	p := &BackoffPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Minute}
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", "7")
+
+	delay, retry := p.Retry(1, res, ErrServerUnavailable)
+	if !retry || 7*time.Second != delay {
+		t.Errorf("Expected Retry-After delay of 7s, found %v (retry=%v)", delay, retry)
+	}
+}
+
+func TestBackoffPolicy_Retry_customRetryable(t *testing.T) {
+	p := &BackoffPolicy{
+		MaxAttempts: 2,
+		Retryable: func(err error) bool {
+			return errors.Is(err, ErrNonJSONResponse)
+		},
+	}
+
+	if _, retry := p.Retry(1, nil, &DecodeError{}); !retry {
+		t.Error("Expected custom Retryable to allow retry")
+	}
+	if _, retry := p.Retry(1, nil, ErrServerUnavailable); retry {
+		t.Error("Expected custom Retryable to deny retry")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err    error
+		expect bool
+	}{
+		{nil, false},
+		{ErrServerUnavailable, true},
+		{ErrInvalidAppID, false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+	}
+
+	for _, test := range tests {
+		if r := IsRetryable(test.err); test.expect != r {
+			t.Errorf("Expected IsRetryable(%v) = %v, found %v", test.err, test.expect, r)
+		}
+	}
+}
@@ -2,18 +2,18 @@ package trimet
 
 type Route struct {
 	// The route's number.
-	ID int `json:"route"`
+	ID int `json:"route" xml:"route,attr"`
 
 	// The route's description.
-	Description string `json:"desc"`
+	Description string `json:"desc" xml:"desc,attr"`
 
 	// The type of the route, either 'B' for bus, or 'R' for fixed guideway
 	// (either rail or aerial tram).
-	Type string `json:"type"`
+	Type string `json:"type" xml:"type,attr"`
 
 	// Indicates if this route has a detour in effect.
-	Detour bool `json:"detour"`
+	Detour bool `json:"detour" xml:"detour,attr"`
 
 	// Information for each route direction.
-	Directions []Direction `json:"dir"`
+	Directions []Direction `json:"dir" xml:"dir"`
 }
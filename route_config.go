@@ -1,5 +1,11 @@
 package trimet
 
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
 // RoutesService retrieves a list of routes being reported by TransitTracker
 // from the active schedule, optionally a list of directions for those routes
 // and stops in each of those directions.
@@ -44,17 +50,54 @@ type RouteConfigRequest struct {
 
 type RouteConfigResponse struct {
 	Response
-	Routes []Route `json:"route"`
+	Routes []Route `json:"route" xml:"route"`
 }
 
 type routeConfigResponseResults struct {
 	Results *RouteConfigResponse `json:"resultSet,omitempty"`
 }
 
+// UnmarshalXML decodes the XML result set, which is the document root.
+func (r *routeConfigResponseResults) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	r.Results = new(RouteConfigResponse)
+	return d.DecodeElement(r.Results, &start)
+}
+
+// Validate checks the request's route numbers, direction and sequence range.
+func (r *RouteConfigRequest) Validate() error {
+	v := new(ValidationError)
+	for _, route := range r.Routes {
+		if route < 0 {
+			v.add("Routes", fmt.Sprintf("invalid route number %d", route))
+		}
+	}
+	switch r.Direction {
+	case "", "0", "1", "true", "yes":
+	default:
+		v.add("Direction", fmt.Sprintf("must be one of 0, 1, true or yes, found %q", r.Direction))
+	}
+	if r.StartSequence < 0 {
+		v.add("StartSequence", "must not be negative")
+	}
+	if r.EndSequence < 0 {
+		v.add("EndSequence", "must not be negative")
+	}
+	if r.EndSequence > 0 && r.StartSequence > r.EndSequence {
+		v.add("StartSequence", fmt.Sprintf("%d is greater than EndSequence %d",
+			r.StartSequence, r.EndSequence))
+	}
+	return v.err()
+}
+
 // Get latest route information.
 func (s *RoutesService) Get(r *RouteConfigRequest) (*RouteConfigResponse, error) {
+	return s.GetContext(context.Background(), r)
+}
+
+// GetContext gets latest route information, aborting if ctx is done.
+func (s *RoutesService) GetContext(ctx context.Context, r *RouteConfigRequest) (*RouteConfigResponse, error) {
 	response := new(routeConfigResponseResults)
-	err := s.client.Get("routeConfig", r, response)
+	err := s.client.GetContext(ctx, "routeConfig", r, response)
 	if nil != err {
 		return nil, err
 	}
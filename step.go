@@ -0,0 +1,16 @@
+package trimet
+
+// A Step is a single direction followed while walking a Leg.
+type Step struct {
+	// The street followed by the step.
+	Street string `xml:"streetName"`
+
+	// The direction to turn relative to the previous step, such as "left".
+	RelativeDirection string `xml:"relativeDirection"`
+
+	// The compass direction of travel, such as "north".
+	AbsoluteDirection string `xml:"absoluteDirection"`
+
+	// Distance travelled in miles.
+	Distance float64 `xml:"distance"`
+}
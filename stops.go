@@ -1,5 +1,11 @@
 package trimet
 
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
 // StopsService returns stops that are within a geographically defined area or
 // within a distance of a point.
 //
@@ -38,17 +44,71 @@ type StopsRequest struct {
 
 type StopsResponse struct {
 	Response
-	Locations []Location `json:"location"`
+	Locations []Location `json:"location" xml:"location"`
 }
 
 type stopsResponseResults struct {
 	Results *StopsResponse `json:"resultSet,omitempty"`
 }
 
+// UnmarshalXML decodes the XML result set, which is the document root.
+func (r *stopsResponseResults) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	r.Results = new(StopsResponse)
+	return d.DecodeElement(r.Results, &start)
+}
+
+// Validate checks that the request describes exactly one search area.
+func (r *StopsRequest) Validate() error {
+	v := new(ValidationError)
+	hasBox, hasPoint := 0 != len(r.BoundingBox), 0 != len(r.LonLat)
+
+	switch {
+	case hasBox && hasPoint:
+		v.add("BoundingBox", "must not be combined with LonLat")
+	case !hasBox && !hasPoint:
+		v.add("BoundingBox", "either BoundingBox or LonLat is required")
+	}
+
+	if hasBox {
+		if 4 != len(r.BoundingBox) {
+			v.add("BoundingBox", fmt.Sprintf("requires exactly 4 values, found %d", len(r.BoundingBox)))
+		} else if r.BoundingBox[0] > r.BoundingBox[2] || r.BoundingBox[1] > r.BoundingBox[3] {
+			v.add("BoundingBox", "minimum corner must not exceed maximum corner")
+		}
+	}
+
+	if hasPoint {
+		if 2 != len(r.LonLat) {
+			v.add("LonLat", fmt.Sprintf("requires exactly 2 values, found %d", len(r.LonLat)))
+		}
+		switch {
+		case 0 != r.Feet && 0 != r.Meters:
+			v.add("Feet", "must not be combined with Meters")
+		case 0 == r.Feet && 0 == r.Meters:
+			v.add("Feet", "either Feet or Meters is required with LonLat")
+		}
+	} else if 0 != r.Feet || 0 != r.Meters {
+		v.add("Feet", "Feet and Meters may only be used with LonLat")
+	}
+
+	if r.Feet < 0 {
+		v.add("Feet", "must not be negative")
+	}
+	if r.Meters < 0 {
+		v.add("Meters", "must not be negative")
+	}
+	return v.err()
+}
+
 // Get latest stop information.
 func (s *StopsService) Get(r *StopsRequest) (*StopsResponse, error) {
+	return s.GetContext(context.Background(), r)
+}
+
+// GetContext gets latest stop information, aborting if ctx is done.
+func (s *StopsService) GetContext(ctx context.Context, r *StopsRequest) (*StopsResponse, error) {
 	response := new(stopsResponseResults)
-	err := s.client.Get("stops", r, response)
+	err := s.client.GetContext(ctx, "stops", r, response)
 	if nil != err {
 		return nil, err
 	}
@@ -1,7 +1,9 @@
 package trimet
 
 import (
+	"encoding/xml"
 	"errors"
+	"strconv"
 	"time"
 )
 
@@ -16,12 +18,40 @@ type Time struct {
 const trimetTime = `2006-01-02T15:04:05.999-0700`
 
 // UnmarshalJSON parses a TriMet time into a time.Time.
+//
+// Version 1 services report times as strings in the TriMet format, while
+// version 2 services report milliseconds since the Unix epoch.
 func (t *Time) UnmarshalJSON(data []byte) error {
-	parsed, err := time.Parse(`"`+trimetTime+`"`, string(data))
-	if nil == err {
-		t.Time = new(time.Time)
-		*t.Time = parsed
+	value := string(data)
+	if unquoted, err := strconv.Unquote(value); nil == err {
+		value = unquoted
+	}
+	return t.parse(value)
+}
+
+// UnmarshalXMLAttr parses a TriMet time attribute into a time.Time.
+//
+// XML responses report times either in the TriMet format or as milliseconds
+// since the Unix epoch.
+func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
+	return t.parse(attr.Value)
+}
+
+// parse parses value as either a TriMet formatted time, milliseconds since
+// the Unix epoch, or an RFC3339 time as written by MarshalJSON.
+func (t *Time) parse(value string) error {
+	parsed, err := time.Parse(trimetTime, value)
+	if nil != err {
+		if ms, msErr := strconv.ParseInt(value, 10, 64); nil == msErr {
+			parsed, err = time.Unix(0, ms*int64(time.Millisecond)), nil
+		} else if rfc, rfcErr := time.Parse(time.RFC3339Nano, value); nil == rfcErr {
+			parsed, err = rfc, nil
+		} else {
+			return err
+		}
 	}
+	t.Time = new(time.Time)
+	*t.Time = parsed
 	return err
 }
 
@@ -1,6 +1,7 @@
 package trimet
 
 import (
+	"encoding/xml"
 	"testing"
 	"time"
 )
@@ -81,3 +82,50 @@ func TestMarshalTime(t *testing.T) {
 			newTime, expected, string(timestamp))
 	}
 }
+
+func TestUnmarshalXMLAttrTime(t *testing.T) {
+	PST, _ := time.LoadLocation("America/Los_Angeles")
+	dt20140119120000 := time.Date(2014, 01, 19, 12, 0, 0, 0, PST)
+
+	for _, value := range []string{"2014-01-19T12:00:00.000-0800", "1390161600000"} {
+		newTime := new(Time)
+		err := newTime.UnmarshalXMLAttr(xml.Attr{Value: value})
+		if nil != err {
+			t.Fatalf("Unexpected error unmarshaling time %v: %v", value, err)
+		}
+		if !dt20140119120000.Equal(*newTime.Time) {
+			t.Errorf("Expected %v to unmarshal to %v, found %v", value, dt20140119120000, newTime.Time)
+		}
+	}
+
+	if err := new(Time).UnmarshalXMLAttr(xml.Attr{Value: "noon"}); nil == err {
+		t.Error("Expected error unmarshaling invalid time")
+	}
+}
+
+func TestUnmarshalTime_milliseconds(t *testing.T) {
+	newTime := new(Time)
+	if err := newTime.UnmarshalJSON([]byte(`1390161600000`)); nil != err {
+		t.Fatalf("Unexpected error unmarshaling milliseconds: %v", err)
+	}
+
+	PST, _ := time.LoadLocation("America/Los_Angeles")
+	dt20140119120000 := time.Date(2014, 01, 19, 12, 0, 0, 0, PST)
+	if !dt20140119120000.Equal(*newTime.Time) {
+		t.Errorf("Expected %v, found %v", dt20140119120000, newTime.Time)
+	}
+}
+
+func TestUnmarshalTime_marshaled(t *testing.T) {
+	PST, _ := time.LoadLocation("America/Los_Angeles")
+	dt20140119120000 := time.Date(2014, 01, 19, 12, 0, 0, 0, PST)
+
+	timestamp, _ := NewTime(dt20140119120000).MarshalJSON()
+	newTime := new(Time)
+	if err := newTime.UnmarshalJSON(timestamp); nil != err {
+		t.Fatalf("Unexpected error unmarshaling %s: %v", timestamp, err)
+	}
+	if !dt20140119120000.Equal(*newTime.Time) {
+		t.Errorf("Expected %v, found %v", dt20140119120000, newTime.Time)
+	}
+}
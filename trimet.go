@@ -1,22 +1,29 @@
 package trimet
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
-	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
 
 const (
-	libraryVersion = "0.1"
-	defaultBaseURL = "http://developer.trimet.org/ws/V1/"
-	userAgent      = "gotrimet/" + libraryVersion
-	mediaType      = "application/json"
+	libraryVersion   = "0.1"
+	defaultBaseURL   = "http://developer.trimet.org/ws/V1/"
+	defaultBaseURLV2 = "http://developer.trimet.org/ws/V2/"
+	userAgent        = "gotrimet/" + libraryVersion
+	mediaType        = "application/json"
+	xmlMediaType     = "application/xml"
 )
 
 // A Client manages communication with the TriMet API.
@@ -32,14 +39,46 @@ type Client struct {
 	// always be specified with a trailing slash.
 	BaseURL *url.URL
 
+	// Base URL for version 2 API requests.  BaseURLV2 should always be
+	// specified with a trailing slash.
+	BaseURLV2 *url.URL
+
 	// User agent used when communicating with the TriMet API.
 	UserAgent string
 
+	// Policy used to retry failed requests.  If nil, requests are not
+	// retried.
+	RetryPolicy RetryPolicy
+
+	// Limiter applied before each request is sent, including retries.  If
+	// nil, requests are not throttled.
+	RateLimiter RateLimiter
+
+	// Cache consulted by Get for previously fetched responses.  If nil,
+	// responses are not cached.
+	Cache Cache
+
+	// Time to live of cached responses for each endpoint, such as
+	// "routeConfig".  Responses from endpoints without a TTL are not cached.
+	// If nil, DefaultCacheTTLs is used.
+	CacheTTLs map[string]time.Duration
+
+	// Interceptors which observe and may modify requests and responses, in
+	// the order they are applied to requests.
+	Interceptors []Interceptor
+
+	// Requests sent to each endpoint.
+	usage usage
+
 	// Services used for talking to different parts of the TriMet API.
-	Arrivals *ArrivalsService
-	Detours  *DetoursService
-	Routes   *RoutesService
-	Stops    *StopsService
+	Alerts      *AlertsService
+	Arrivals    *ArrivalsService
+	ArrivalsV2  *ArrivalsV2Service
+	Detours     *DetoursService
+	Routes      *RoutesService
+	Stops       *StopsService
+	TripPlanner *TripPlannerService
+	Vehicles    *VehiclesService
 }
 
 // NewClient returns a new TriMet API client.
@@ -53,17 +92,23 @@ func NewClient(appID string, httpClient *http.Client) *Client {
 		httpClient = http.DefaultClient
 	}
 	baseURL, _ := url.Parse(defaultBaseURL)
+	baseURLV2, _ := url.Parse(defaultBaseURLV2)
 
 	c := &Client{
-		client: httpClient,
-		appID: appID,
-		BaseURL: baseURL,
+		client:    httpClient,
+		appID:     appID,
+		BaseURL:   baseURL,
+		BaseURLV2: baseURLV2,
 		UserAgent: userAgent,
 	}
+	c.Alerts = &AlertsService{client: c}
 	c.Arrivals = &ArrivalsService{client: c}
+	c.ArrivalsV2 = &ArrivalsV2Service{client: c}
 	c.Detours = &DetoursService{client: c}
 	c.Routes = &RoutesService{client: c}
 	c.Stops = &StopsService{client: c}
+	c.TripPlanner = &TripPlannerService{client: c}
+	c.Vehicles = &VehiclesService{client: c}
 	return c
 }
 
@@ -72,8 +117,20 @@ func NewClient(appID string, httpClient *http.Client) *Client {
 // A relative URL can be provided in urlStr, in which case it is resolved
 // relative to the BaseURL of the Client.  Relative URLs should always be
 // specified without a preceding slash.  If specified, the value pointed to by
-// params is included with the request query.
+// params is included with the request query.  Params which implement
+// Validate() error are validated first.
 func (c *Client) NewRequest(method, urlStr string, params interface{}) (*http.Request, error) {
+	return c.NewRequestContext(context.Background(), method, urlStr, params)
+}
+
+// NewRequestContext creates an API request bound to ctx.
+//
+// The context controls the entire lifetime of the request: cancelling it or
+// letting its deadline pass aborts the request when it is sent with Do.
+func (c *Client) NewRequestContext(ctx context.Context, method, urlStr string, params interface{}) (*http.Request, error) {
+	if nil == ctx {
+		return nil, errors.New("Context must not be nil")
+	}
 	if "" == c.appID {
 		return nil, errors.New("Missing required AppID")
 	}
@@ -81,20 +138,40 @@ func (c *Client) NewRequest(method, urlStr string, params interface{}) (*http.Re
 		return nil, errors.New("Requested URL must not be empty")
 	}
 
-	req := newRequest(c.appID)
-	queryVals, err := query.Values(req)
+	paramVals, err := parameterValues(params)
 	if nil != err {
 		return nil, err
 	}
 
-	paramVals, err := parameterValues(params)
+	if v, ok := params.(validator); ok && nil != paramVals {
+		if err := v.Validate(); nil != err {
+			return nil, err
+		}
+	}
+
+	target, err := c.BaseURL.Parse(urlStr)
 	if nil != err {
 		return nil, err
-	} else if nil != paramVals {
-		for k, vals := range paramVals {
-			for _, v := range vals {
-				queryVals.Add(k, v)
-			}
+	}
+
+	// Version 2 services only report JSON, so XML is only ever requested
+	// from version 1 services.
+	req := newRequest(c.appID)
+	if r, ok := params.(xmlRequester); ok && nil != paramVals && r.wantsXML() && !c.isV2(target) {
+		req.JSON = false
+	}
+
+	queryVals, err := query.Values(req)
+	if nil != err {
+		return nil, err
+	}
+
+	// The format is chosen by req, so a JSON field set in params is not
+	// repeated.
+	paramVals.Del("json")
+	for k, vals := range paramVals {
+		for _, v := range vals {
+			queryVals.Add(k, v)
 		}
 	}
 
@@ -105,13 +182,21 @@ func (c *Client) NewRequest(method, urlStr string, params interface{}) (*http.Re
 
 	u := c.BaseURL.ResolveReference(rel)
 
-	httpReq, err := http.NewRequest(method, u.String(), nil)
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	httpReq.Header.Add("Accept", mediaType)
+	if req.JSON {
+		httpReq.Header.Add("Accept", mediaType)
+	} else {
+		httpReq.Header.Add("Accept", xmlMediaType)
+	}
 	httpReq.Header.Add("User-Agent", c.UserAgent)
+
+	if err := c.interceptRequest(httpReq); nil != err {
+		return nil, err
+	}
 	return httpReq, nil
 }
 
@@ -130,6 +215,12 @@ func parameterValues(params interface{}) (url.Values, error) {
 	return query.Values(params)
 }
 
+// isV2 reports whether u addresses a version 2 service under BaseURLV2.
+func (c *Client) isV2(u *url.URL) bool {
+	return nil != c.BaseURLV2 && u.Host == c.BaseURLV2.Host &&
+		strings.HasPrefix(u.Path, c.BaseURLV2.Path)
+}
+
 // urlWithQuery adds the parameters in params as URL query parameters to base.
 // params must be a struct whose fields may contain "url" tags.
 func urlWithQuery(base string, q url.Values) (*url.URL, error) {
@@ -145,31 +236,126 @@ func urlWithQuery(base string, q url.Values) (*url.URL, error) {
 // Do sends an API request and returns the API response.
 //
 // The API response is decoded and stored in the value pointed to by v, or
-// returned as an error if an API error has occurred.
+// returned as an error if an API error has occurred.  If v is a *[]byte the
+// raw response body is stored instead, for formats the Client does not
+// decode itself.
+//
+// If the request's context is cancelled or its deadline is exceeded, the
+// context's error is returned unwrapped so that it can be told apart from
+// transport and API errors.
+//
+// Failed requests are retried as directed by the Client's RetryPolicy.
 func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
+	response, _, err := c.send(req, v)
+	return response, err
+}
+
+// send sends an API request, retrying as directed by the RetryPolicy, and
+// returns the API response along with its raw body.
+func (c *Client) send(req *http.Request, v interface{}) (*http.Response, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		response, data, err := c.do(req, v, attempt)
+		if nil == err || nil == c.RetryPolicy {
+			return response, data, err
+		}
+
+		if nil != req.Context().Err() {
+			return response, data, err
+		}
+
+		delay, retry := c.RetryPolicy.Retry(attempt, response, err)
+		if !retry {
+			return response, data, err
+		}
+
+		if err := sleepContext(req.Context(), delay); nil != err {
+			return nil, nil, err
+		}
+	}
+}
+
+// do makes a single attempt at sending an API request, passing the exchange
+// through the Client's Interceptors.
+func (c *Client) do(req *http.Request, v interface{}, attempt int) (*http.Response, []byte, error) {
+	ex := &Exchange{Request: req, Result: v, Attempt: attempt, Start: time.Now()}
+	ex.Response, ex.Body, ex.Err = c.roundTrip(req, v)
+	ex.Duration = time.Since(ex.Start)
+
+	c.interceptResponse(ex)
+	return ex.Response, ex.Body, ex.Err
+}
+
+// roundTrip sends an API request and decodes the response.
+func (c *Client) roundTrip(req *http.Request, v interface{}) (*http.Response, []byte, error) {
+	if nil != c.RateLimiter {
+		if err := c.RateLimiter.Wait(req.Context()); nil != err {
+			return nil, nil, err
+		}
+	}
+	if nil != req.URL {
+		c.usage.add(c.endpoint(req.URL.Path))
+	}
+
 	response, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		if ctxErr := req.Context().Err(); nil != ctxErr {
+			return nil, nil, ctxErr
+		}
+		return nil, nil, err
 	}
 	defer response.Body.Close()
 
 	data, err := ioutil.ReadAll(response.Body)
 	if nil == err && nil != data {
 		err = CheckResponse(response, data)
-		if nil == err && nil != v {
-			err = json.Unmarshal(data, v)
+		if raw, ok := v.(*[]byte); ok && nil == err {
+			*raw = data
+		} else if nil == err && nil != v {
+			err = decode(req, data, v)
 		}
 	}
-	return response, err
+	return response, data, err
+}
+
+// decode unmarshals data into v using the format requested by req.
+func decode(req *http.Request, data []byte, v interface{}) error {
+	if "true" == req.URL.Query().Get("json") {
+		return decodeJSON(data, v)
+	}
+	return decodeXML(data, v)
+}
+
+// decodeJSON unmarshals data into v, reporting syntax errors as a DecodeError.
+func decodeJSON(data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if _, ok := err.(*json.SyntaxError); ok {
+		return &DecodeError{Err: err, Body: data}
+	}
+	return err
+}
+
+// decodeXML unmarshals data into v, reporting syntax errors as a DecodeError.
+func decodeXML(data []byte, v interface{}) error {
+	err := xml.Unmarshal(data, v)
+	if _, ok := err.(*xml.SyntaxError); ok || io.EOF == err {
+		return &DecodeError{Err: err, Body: data, XML: true}
+	}
+	return err
+}
+
+// isXML reports whether data appears to be an XML document.
+func isXML(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && '<' == trimmed[0]
 }
 
 // CheckResponse checks the API response for errors, and returns them if
 // present.
 //
-// A response is considered an error if it has a status code outside the 200
-// range.  API error responses are expected to have either no response body, or
-// a JSON response body that maps to ErrorResponse.  Any other response body
-// will be silently ignored.
+// A response is considered an error if it contains a TriMet error message, in
+// which case an *ErrorResponse is returned, or if it has a status code outside
+// the 200 range, in which case a *StatusError is returned.  Either may be
+// inspected with errors.Is to determine the class of error.
 func CheckResponse(r *http.Response, data []byte) error {
 	errorResponse := newErrorResponse(r)
 	err := json.Unmarshal(data, errorResponse)
@@ -177,31 +363,72 @@ func CheckResponse(r *http.Response, data []byte) error {
 		return errorResponse
 	}
 
+	results := &errorResponseResults{Results: newErrorResponse(r)}
+	err = json.Unmarshal(data, results)
+	if nil == err && "" != results.Results.Message.Content {
+		return results.Results
+	}
+
+	if isXML(data) {
+		errorResponse = newErrorResponse(r)
+		err = xml.Unmarshal(data, errorResponse)
+		if nil == err && "" != errorResponse.Message.Content {
+			return errorResponse
+		}
+
+		plannerError := new(plannerErrorResults)
+		err = xml.Unmarshal(data, plannerError)
+		if nil == err && "" != plannerError.Message {
+			errorResponse = newErrorResponse(r)
+			errorResponse.Message.Content = plannerError.Message
+			return errorResponse
+		}
+	}
+
 	if c := r.StatusCode; c < 200 || c > 299 {
-		return fmt.Errorf("Encountered HTTP error status %v", c)
+		return &StatusError{Response: r, Body: data}
 	}
 
 	return nil
 }
 
-// Do sends an API request and returns the API response.
+// Get sends a GET request for url with the parameters in request.
 //
-// The API response is decoded and stored in the value pointed to by v, or
-// returned as an error if an API error has occurred.
+// The API response is decoded and stored in the value pointed to by response,
+// or returned as an error if an API error has occurred.
 func (c *Client) Get(url string, request interface{}, response interface{}) error {
+	return c.GetContext(context.Background(), url, request, response)
+}
+
+// GetContext is like Get, but the request is bound to ctx.
+func (c *Client) GetContext(ctx context.Context, url string, request interface{}, response interface{}) error {
 	if nil == response {
 		return errors.New("GET expects response data")
 	}
 
-	req, err := c.NewRequest("GET", url, request)
+	req, err := c.NewRequestContext(ctx, "GET", url, request)
 	if err != nil {
 		return err
 	}
 
-	_, err = c.Do(req, response)
+	key, ttl := c.cacheKey(req)
+	if 0 != ttl {
+		if entry, ok := c.Cache.Get(key); ok && entry.Fresh(time.Now()) {
+			ex := &Exchange{Request: req, Body: entry.Body, Result: response, Cached: true, Start: time.Now()}
+			ex.Err = decode(req, entry.Body, response)
+			c.interceptResponse(ex)
+			return ex.Err
+		}
+	}
+
+	_, data, err := c.send(req, response)
 	if err != nil {
 		return err
 	}
 
+	if 0 != ttl {
+		c.Cache.Set(key, newCacheEntry(data, ttl, time.Now()))
+	}
+
 	return nil
 }
@@ -2,6 +2,7 @@ package trimet
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,6 +11,7 @@ import (
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var (
@@ -35,6 +37,7 @@ func setup() {
 	client = NewClient(testAppID, nil)
 	url, _ := url.Parse(server.URL)
 	client.BaseURL = url
+	client.BaseURLV2, _ = client.BaseURL.Parse("/V2/")
 }
 
 // teardown closes the test HTTP server.
@@ -268,6 +271,57 @@ func TestDo_trimetError(t *testing.T) {
 	}
 }
 
+func TestDo_canceledContext(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Unexpected request sent with canceled context")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := client.NewRequestContext(ctx, "GET", "/", nil)
+	_, err := client.Do(req, nil)
+
+	if context.Canceled != err {
+		t.Errorf("Expected context.Canceled, found %v", err)
+	}
+}
+
+func TestDo_deadlineExceeded(t *testing.T) {
+	setup()
+	defer teardown()
+
+	done := make(chan struct{})
+	defer close(done)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, _ := client.NewRequestContext(ctx, "GET", "/", nil)
+	_, err := client.Do(req, nil)
+
+	if context.DeadlineExceeded != err {
+		t.Errorf("Expected context.DeadlineExceeded, found %v", err)
+	}
+}
+
+func TestNewRequestContext_nilContext(t *testing.T) {
+	c := NewClient(testAppID, nil)
+	_, err := c.NewRequestContext(nil, "GET", "arrivals", nil)
+	if err == nil {
+		t.Error("Expected error to be returned for nil context")
+	}
+}
+
 func TestCheckResponse(t *testing.T) {
 	res := &http.Response{
 		Request:    &http.Request{},
@@ -323,6 +377,19 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetContext_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res := new(int)
+	if err := client.GetContext(ctx, "/", nil, res); context.Canceled != err {
+		t.Errorf("Expected context.Canceled, found %v", err)
+	}
+}
+
 func TestGet_badResponseArg(t *testing.T) {
 	err := client.Get("/", nil, nil)
 	if nil == err {
@@ -0,0 +1,105 @@
+package trimettest
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// trimetTime is the format TriMet uses for times in JSON responses.
+const trimetTime = `2006-01-02T15:04:05.000-0700`
+
+var timeType = reflect.TypeOf(trimet.Time{})
+
+// marshal encodes v as TriMet would, with times in TriMet's format and empty
+// fields omitted.
+func marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(wire(reflect.ValueOf(v)))
+}
+
+// wire converts v into maps, slices and scalars keyed by the JSON field names
+// of the gotrimet types.
+func wire(v reflect.Value) interface{} {
+	for reflect.Ptr == v.Kind() || reflect.Interface == v.Kind() {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if timeType == v.Type() {
+			t := v.Interface().(trimet.Time)
+			if nil == t.Time {
+				return nil
+			}
+			return t.Format(trimetTime)
+		}
+		fields := make(map[string]interface{})
+		addFields(fields, v)
+		return fields
+
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = wire(v.Index(i))
+		}
+		return items
+
+	case reflect.Map:
+		items := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			items[k.String()] = wire(v.MapIndex(k))
+		}
+		return items
+	}
+	return v.Interface()
+}
+
+// addFields adds the exported fields of struct v to fields, flattening
+// embedded structs.
+func addFields(fields map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if "" != f.PkgPath {
+			continue
+		}
+		if f.Anonymous && reflect.Struct == f.Type.Kind() {
+			addFields(fields, v.Field(i))
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if "-" == name {
+			continue
+		}
+		if "" == name {
+			name = f.Name
+		}
+
+		fv := v.Field(i)
+		if isEmpty(fv) {
+			continue
+		}
+		if w := wire(fv); nil != w {
+			fields[name] = w
+		}
+	}
+}
+
+// isEmpty reports whether v should be omitted from a response.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return 0 == v.Len()
+	case reflect.Struct:
+		return v.IsZero()
+	}
+	return false
+}
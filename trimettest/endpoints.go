@@ -0,0 +1,249 @@
+package trimettest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// serveArrivals reports the arrivals at the requested stops which have not
+// yet passed.  The caller must hold s.mu.
+func (s *Server) serveArrivals(q url.Values) (interface{}, error) {
+	ids, err := intList(q.Get("locIDs"))
+	if nil != err {
+		return nil, err
+	}
+	if 0 == len(ids) {
+		return nil, errors.New("Missing required argument locIDs")
+	}
+	if len(ids) > trimet.MaxLocationIDs {
+		return nil, fmt.Errorf("Too many location ids (maximum %d)", trimet.MaxLocationIDs)
+	}
+
+	response := &trimet.ArrivalsResponse{Response: s.response()}
+	for _, id := range ids {
+		l, ok := s.locations[id]
+		if !ok {
+			return nil, fmt.Errorf("Location id not found %d", id)
+		}
+		l.Routes = nil
+		response.Locations = append(response.Locations, l)
+	}
+
+	for _, a := range s.arrivals {
+		if !containsInt(ids, a.Location) {
+			continue
+		}
+		at := a.Scheduled
+		if nil != a.Estimated {
+			at = a.Estimated
+		}
+		if nil != at && nil != at.Time && at.Before(s.now) {
+			continue
+		}
+		response.Arrivals = append(response.Arrivals, a)
+	}
+
+	sort.SliceStable(response.Arrivals, func(i, j int) bool {
+		return arrivalTime(response.Arrivals[i]) < arrivalTime(response.Arrivals[j])
+	})
+	return response, nil
+}
+
+// arrivalTime returns the best known time of a, in Unix nanoseconds.
+func arrivalTime(a trimet.Arrival) int64 {
+	for _, t := range []*trimet.Time{a.Estimated, a.Scheduled} {
+		if nil != t && nil != t.Time {
+			return t.UnixNano()
+		}
+	}
+	return math.MaxInt64
+}
+
+// serveDetours reports the detours applying to the requested routes, or all
+// detours.  The caller must hold s.mu.
+func (s *Server) serveDetours(q url.Values) (interface{}, error) {
+	routes, err := intList(q.Get("routes"))
+	if nil != err {
+		return nil, err
+	}
+
+	response := &trimet.DetoursResponse{Response: s.response()}
+	for _, d := range s.detours {
+		if 0 == len(routes) || detourApplies(d, routes) {
+			response.Detours = append(response.Detours, d)
+		}
+	}
+	return response, nil
+}
+
+func detourApplies(d trimet.Detour, routes []int) bool {
+	for _, r := range d.Routes {
+		if containsInt(routes, r.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveRouteConfig reports the requested routes, including directions and
+// stops as requested.  The caller must hold s.mu.
+func (s *Server) serveRouteConfig(q url.Values) (interface{}, error) {
+	ids, err := intList(q.Get("routes"))
+	if nil != err {
+		return nil, err
+	}
+	startSeq, err := intList(q.Get("startSeq"))
+	if nil != err {
+		return nil, err
+	}
+	endSeq, err := intList(q.Get("endSeq"))
+	if nil != err {
+		return nil, err
+	}
+
+	dir := q.Get("dir")
+	timePoints := "" != q.Get("tp")
+	stops := timePoints || "" != q.Get("stops")
+
+	response := &trimet.RouteConfigResponse{Response: s.response()}
+	for _, r := range s.routes {
+		if 0 != len(ids) && !containsInt(ids, r.ID) {
+			continue
+		}
+
+		var dirs []trimet.Direction
+		for _, d := range r.Directions {
+			if "" == dir || ("0" == dir && 0 != d.Number) || ("1" == dir && 1 != d.Number) {
+				continue
+			}
+
+			var locations []trimet.Location
+			for _, l := range d.Locations {
+				switch {
+				case !stops,
+					timePoints && !l.TimePoint,
+					0 != len(startSeq) && l.Sequence < startSeq[0],
+					0 != len(endSeq) && l.Sequence > endSeq[0]:
+					continue
+				}
+				locations = append(locations, l)
+			}
+			d.Locations = locations
+			dirs = append(dirs, d)
+		}
+		r.Directions = dirs
+		response.Routes = append(response.Routes, r)
+	}
+	return response, nil
+}
+
+// serveStops reports the stops within the requested area.  The caller must
+// hold s.mu.
+func (s *Server) serveStops(q url.Values) (interface{}, error) {
+	bbox, err := floatList(q.Get("bbox"))
+	if nil != err {
+		return nil, err
+	}
+	ll, err := floatList(q.Get("ll"))
+	if nil != err {
+		return nil, err
+	}
+	radius, err := floatList(q.Get("feet"))
+	if nil != err {
+		return nil, err
+	}
+	if meters, err := floatList(q.Get("meters")); nil != err {
+		return nil, err
+	} else if 0 != len(meters) {
+		radius = []float64{meters[0] / 0.3048}
+	}
+
+	var within func(trimet.Location) bool
+	switch {
+	case 4 == len(bbox):
+		within = func(l trimet.Location) bool {
+			return l.Lon >= bbox[0] && l.Lat >= bbox[1] && l.Lon <= bbox[2] && l.Lat <= bbox[3]
+		}
+	case 2 == len(ll) && 1 == len(radius):
+		within = func(l trimet.Location) bool {
+			return distanceFeet(ll[1], ll[0], l.Lat, l.Lon) <= radius[0]
+		}
+	default:
+		return nil, errors.New("Invalid arguments: requires bbox or ll with feet or meters")
+	}
+
+	showDirs := "true" == q.Get("showRouteDirs")
+	showRoutes := showDirs || "true" == q.Get("showRoutes")
+
+	var ids []int
+	for id, l := range s.locations {
+		if within(l) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	response := &trimet.StopsResponse{Response: s.response()}
+	for _, id := range ids {
+		l := s.locations[id]
+		if !showRoutes {
+			l.Routes = nil
+		} else if !showDirs {
+			routes := make([]trimet.Route, len(l.Routes))
+			for i, r := range l.Routes {
+				r.Directions = nil
+				routes[i] = r
+			}
+			l.Routes = routes
+		}
+		response.Locations = append(response.Locations, l)
+	}
+	return response, nil
+}
+
+// serveVehicles reports the vehicles serving the requested routes, or all
+// vehicles.  The caller must hold s.mu.
+func (s *Server) serveVehicles(q url.Values) (interface{}, error) {
+	routes, err := intList(q.Get("routes"))
+	if nil != err {
+		return nil, err
+	}
+	ids, err := intList(q.Get("ids"))
+	if nil != err {
+		return nil, err
+	}
+	since, err := intList(q.Get("since"))
+	if nil != err {
+		return nil, err
+	}
+
+	response := &trimet.VehiclesResponse{Response: s.response()}
+	for _, v := range s.vehicles {
+		switch {
+		case 0 != len(routes) && !containsInt(routes, v.Route),
+			0 != len(ids) && !containsInt(ids, v.ID),
+			0 != len(since) && (nil == v.LocationTime || nil == v.LocationTime.Time ||
+				v.LocationTime.UnixNano()/int64(time.Millisecond) <= int64(since[0])):
+			continue
+		}
+		response.Vehicles = append(response.Vehicles, v)
+	}
+	return response, nil
+}
+
+// distanceFeet returns the great circle distance between two points.
+func distanceFeet(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusFeet = 20902231
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusFeet * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,185 @@
+package trimettest
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trimettest")
+	if nil != err {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cassette.json")
+
+	s := newTestServer(t)
+	if err := s.LoadFixtures("../testdata"); nil != err {
+		t.Fatalf("Unexpected error loading fixtures: %v", err)
+	}
+
+	rec, err := NewRecorder(file, Record, nil)
+	if nil != err {
+		t.Fatalf("Unexpected error creating recorder: %v", err)
+	}
+
+	c := trimet.NewClient(testAppID, &http.Client{Transport: rec})
+	c.BaseURL = s.Client().BaseURL
+
+	req := &trimet.ArrivalsRequest{LocationIDs: []int{8989}}
+	recorded, err := c.Arrivals.Get(req)
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error while recording: %v", err)
+	}
+	if err := rec.Save(); nil != err {
+		t.Fatalf("Unexpected error saving cassette: %v", err)
+	}
+	s.Close()
+
+	data, _ := ioutil.ReadFile(file)
+	if strings.Contains(string(data), testAppID) {
+		t.Errorf("Expected cassette to omit the appID:\n%s", data)
+	}
+
+	rec, err = NewRecorder(file, Replay, nil)
+	if nil != err {
+		t.Fatalf("Unexpected error loading cassette: %v", err)
+	}
+
+	c = trimet.NewClient("another", &http.Client{Transport: rec})
+	c.BaseURL = s.Client().BaseURL
+
+	replayed, err := c.Arrivals.Get(req)
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error while replaying: %v", err)
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("Expected replayed response %+v, found %+v", recorded, replayed)
+	}
+
+	_, err = c.Arrivals.Get(&trimet.ArrivalsRequest{LocationIDs: []int{10775}})
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Expected ErrNotRecorded for unrecorded request, found %v", err)
+	}
+}
+
+func TestRecorder_replayOrder(t *testing.T) {
+	rec := &Recorder{
+		mode:   Replay,
+		played: make(map[int]bool),
+		cassette: Cassette{Exchanges: []Exchange{
+			{Method: "GET", Endpoint: "V1/detours", Status: 200, Body: "first"},
+			{Method: "GET", Endpoint: "V1/detours", Status: 200, Body: "second"},
+		}},
+	}
+
+	var bodies []string
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", "http://example.com/ws/V1/detours?appID=x", nil)
+		res, err := rec.RoundTrip(req)
+		if nil != err {
+			t.Fatalf("Unexpected replay error: %v", err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		bodies = append(bodies, string(body))
+	}
+
+	expect := []string{"first", "second", "second"}
+	if !reflect.DeepEqual(expect, bodies) {
+		t.Errorf("Expected replayed bodies %v, found %v", expect, bodies)
+	}
+}
+
+func TestRecorder_versions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trimettest")
+	if nil != err {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cassette.json")
+
+	s := newTestServer(t)
+	if err := s.LoadFixtures("../testdata"); nil != err {
+		t.Fatalf("Unexpected error loading fixtures: %v", err)
+	}
+	v2, err := ioutil.ReadFile("../testdata/V2/arrivals.json")
+	if nil != err {
+		t.Fatal("Unable to read ../testdata/V2/arrivals.json")
+	}
+	s.HandleFunc("/V2/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(v2)
+	})
+
+	rec, err := NewRecorder(file, Record, nil)
+	if nil != err {
+		t.Fatalf("Unexpected error creating recorder: %v", err)
+	}
+	c := trimet.NewClient(testAppID, &http.Client{Transport: rec})
+	c.BaseURL, c.BaseURLV2 = s.Client().BaseURL, s.Client().BaseURLV2
+
+	req := &trimet.ArrivalsRequest{LocationIDs: []int{8989}}
+	reqV2 := &trimet.ArrivalsV2Request{LocationIDs: []int{8989}}
+	recorded, err := c.Arrivals.Get(req)
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error while recording: %v", err)
+	}
+	recordedV2, err := c.ArrivalsV2.Get(reqV2)
+	if nil != err {
+		t.Fatalf("ArrivalsV2.Get returned error while recording: %v", err)
+	}
+	if err := rec.Save(); nil != err {
+		t.Fatalf("Unexpected error saving cassette: %v", err)
+	}
+	s.Close()
+
+	var endpoints []string
+	for _, ex := range rec.Exchanges() {
+		endpoints = append(endpoints, ex.Endpoint)
+	}
+	if expect := []string{"arrivals", "V2/arrivals"}; !reflect.DeepEqual(expect, endpoints) {
+		t.Errorf("Expected endpoints %v, found %v", expect, endpoints)
+	}
+
+	rec, err = NewRecorder(file, Replay, nil)
+	if nil != err {
+		t.Fatalf("Unexpected error loading cassette: %v", err)
+	}
+	c = trimet.NewClient(testAppID, &http.Client{Transport: rec})
+	c.BaseURL, c.BaseURLV2 = s.Client().BaseURL, s.Client().BaseURLV2
+
+	replayedV2, err := c.ArrivalsV2.Get(reqV2)
+	if nil != err {
+		t.Fatalf("ArrivalsV2.Get returned error while replaying: %v", err)
+	}
+	if !reflect.DeepEqual(recordedV2, replayedV2) {
+		t.Errorf("Expected replayed V2 response %+v, found %+v", recordedV2, replayedV2)
+	}
+	replayed, err := c.Arrivals.Get(req)
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error while replaying: %v", err)
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("Expected replayed response %+v, found %+v", recorded, replayed)
+	}
+}
+
+func TestEndpointPath(t *testing.T) {
+	for p, expect := range map[string]string{
+		"/ws/V1/arrivals":          "V1/arrivals",
+		"/ws/V2/arrivals":          "V2/arrivals",
+		"/ws/V1/trips/tripplanner": "V1/trips/tripplanner",
+		"/arrivals":                "arrivals",
+	} {
+		if endpoint := endpointPath(p); expect != endpoint {
+			t.Errorf("Expected endpoint of %v to be %v, found %v", p, expect, endpoint)
+		}
+	}
+}
@@ -0,0 +1,422 @@
+// Package trimettest provides an in-process fake of the TriMet web services
+// for testing code built on gotrimet.
+//
+// A Server answers arrivals, detours, routeConfig, stops and vehicles
+// requests either from fixture files or from state programmed by the test:
+//
+//	srv := trimettest.NewServer("abc123")
+//	defer srv.Close()
+//
+//	srv.AddLocation(trimet.Location{ID: 8989, Description: "NW 23rd & Marshall"})
+//	srv.AddArrival(trimet.Arrival{Location: 8989, Route: 15, Block: 1537,
+//		Scheduled: trimet.NewTime(srv.Now().Add(5 * time.Minute))})
+//
+//	arrivals, err := srv.Client().Arrivals.Get(&trimet.ArrivalsRequest{
+//		LocationIDs: []int{8989},
+//	})
+//
+// Responses are always JSON.
+package trimettest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+// Endpoints served by a Server.  Version 2 endpoints are prefixed with
+// their version, as recorded by a Recorder.
+var Endpoints = []string{"arrivals", "detours", "routeConfig", "stops", "V2/vehicles"}
+
+// A Server is a fake TriMet API server.
+type Server struct {
+	*httptest.Server
+
+	// AppID required of requests.
+	AppID string
+
+	mu        sync.Mutex
+	mux       *http.ServeMux
+	now       time.Time
+	locations map[int]trimet.Location
+	arrivals  []trimet.Arrival
+	detours   []trimet.Detour
+	routes    []trimet.Route
+	vehicles  []trimet.Vehicle
+	fixtures  map[string][]byte
+	failures  map[string][]Failure
+	requests  []Request
+}
+
+// A Request records a request received by a Server.
+type Request struct {
+	Method   string
+	Endpoint string
+	Query    url.Values
+	Header   http.Header
+}
+
+// A Failure is an error returned by a Server in place of a response.
+type Failure struct {
+	// HTTP status of the response.  If zero or 200, Message is reported as
+	// a TriMet errorMessage.
+	Status int
+
+	// Error message.
+	Message string
+}
+
+// NewServer starts a Server which accepts requests with appID.
+//
+// The Server's clock starts at the current time.  It should be closed when
+// no longer needed.
+func NewServer(appID string) *Server {
+	s := &Server{
+		AppID:     appID,
+		mux:       http.NewServeMux(),
+		now:       time.Now(),
+		locations: make(map[int]trimet.Location),
+		fixtures:  make(map[string][]byte),
+		failures:  make(map[string][]Failure),
+	}
+	s.mux.HandleFunc("/arrivals", s.handle("arrivals", s.serveArrivals))
+	s.mux.HandleFunc("/detours", s.handle("detours", s.serveDetours))
+	s.mux.HandleFunc("/routeConfig", s.handle("routeConfig", s.serveRouteConfig))
+	s.mux.HandleFunc("/stops", s.handle("stops", s.serveStops))
+	s.mux.HandleFunc("/V2/vehicles", s.handle("V2/vehicles", s.serveVehicles))
+	s.Server = httptest.NewServer(s.mux)
+	return s
+}
+
+// Client returns a trimet.Client with the Server's AppID which sends
+// requests to the Server.
+func (s *Server) Client() *trimet.Client {
+	c := trimet.NewClient(s.AppID, nil)
+	c.BaseURL, _ = url.Parse(s.URL + "/")
+	c.BaseURLV2, _ = url.Parse(s.URL + "/V2/")
+	return c
+}
+
+// HandleFunc registers an additional handler, so that tests can fake
+// endpoints the Server does not provide.
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// SetFixture serves body verbatim for every request to endpoint, in place of
+// the programmed state.  A nil body restores the programmed state.
+func (s *Server) SetFixture(endpoint string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if nil == body {
+		delete(s.fixtures, endpoint)
+	} else {
+		s.fixtures[endpoint] = body
+	}
+}
+
+// LoadFixtures sets fixtures from the files named after each endpoint, such
+// as "arrivals.json" or "V2/vehicles.json", found in dir.  Missing files are
+// skipped.
+func (s *Server) LoadFixtures(dir string) error {
+	for _, endpoint := range Endpoints {
+		body, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(endpoint)+".json"))
+		if os.IsNotExist(err) {
+			continue
+		} else if nil != err {
+			return err
+		}
+		s.SetFixture(endpoint, body)
+	}
+	return nil
+}
+
+// Now returns the Server's current time, which is reported as the queryTime
+// of responses.
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// SetNow sets the Server's clock.
+func (s *Server) SetNow(now time.Time) {
+	s.mu.Lock()
+	s.now = now
+	s.mu.Unlock()
+}
+
+// Advance moves the Server's clock forward by d.  Arrivals whose time has
+// passed are no longer reported.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	s.now = s.now.Add(d)
+	s.mu.Unlock()
+}
+
+// AddLocation adds or replaces a stop.
+func (s *Server) AddLocation(l trimet.Location) {
+	s.mu.Lock()
+	s.locations[l.ID] = l
+	s.mu.Unlock()
+}
+
+// AddArrival adds an arrival of a vehicle at a stop.  The stop is added if
+// it is not already known.
+func (s *Server) AddArrival(a trimet.Arrival) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.locations[a.Location]; !ok {
+		s.locations[a.Location] = trimet.Location{ID: a.Location}
+	}
+	s.arrivals = append(s.arrivals, a)
+}
+
+// CancelArrival marks the arrivals of block at a stop as canceled, returning
+// whether any were found.
+func (s *Server) CancelArrival(locationID, block int) bool {
+	return s.UpdateArrivals(func(a *trimet.Arrival) {
+		if locationID == a.Location && block == a.Block {
+			a.Status = "canceled"
+			a.Estimated = nil
+		}
+	}) > 0
+}
+
+// UpdateArrivals calls update for every arrival, returning the number of
+// arrivals which were changed.
+func (s *Server) UpdateArrivals(update func(*trimet.Arrival)) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := 0
+	for i := range s.arrivals {
+		before := s.arrivals[i]
+		update(&s.arrivals[i])
+		if !reflect.DeepEqual(before, s.arrivals[i]) {
+			changed++
+		}
+	}
+	return changed
+}
+
+// AddDetour adds or replaces a detour, identified by its ID.
+func (s *Server) AddDetour(d trimet.Detour) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.detours {
+		if d.ID == s.detours[i].ID {
+			s.detours[i] = d
+			return
+		}
+	}
+	s.detours = append(s.detours, d)
+}
+
+// RemoveDetour removes the detour with id, returning whether it was found.
+func (s *Server) RemoveDetour(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.detours {
+		if id == s.detours[i].ID {
+			s.detours = append(s.detours[:i], s.detours[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AddRoute adds or replaces a route, identified by its ID.  Stops listed in
+// its directions are reported by routeConfig requests.
+func (s *Server) AddRoute(r trimet.Route) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.routes {
+		if r.ID == s.routes[i].ID {
+			s.routes[i] = r
+			return
+		}
+	}
+	s.routes = append(s.routes, r)
+}
+
+// AddVehicle adds or replaces a vehicle, identified by its ID.
+func (s *Server) AddVehicle(v trimet.Vehicle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.vehicles {
+		if v.ID == s.vehicles[i].ID {
+			s.vehicles[i] = v
+			return
+		}
+	}
+	s.vehicles = append(s.vehicles, v)
+}
+
+// RemoveVehicle removes the vehicle with id, returning whether it was found.
+func (s *Server) RemoveVehicle(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.vehicles {
+		if id == s.vehicles[i].ID {
+			s.vehicles = append(s.vehicles[:i], s.vehicles[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Fail queues failures to be returned, in order, by the next requests to
+// endpoint.
+func (s *Server) Fail(endpoint string, failures ...Failure) {
+	s.mu.Lock()
+	s.failures[endpoint] = append(s.failures[endpoint], failures...)
+	s.mu.Unlock()
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ResetRequests forgets the requests received so far.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	s.requests = nil
+	s.mu.Unlock()
+}
+
+// handle returns a handler for endpoint which records requests, checks the
+// AppID and serves failures and fixtures before calling serve.
+func (s *Server) handle(endpoint string, serve func(url.Values) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method:   r.Method,
+			Endpoint: endpoint,
+			Query:    r.Form,
+			Header:   r.Header.Clone(),
+		})
+
+		var failure *Failure
+		if queued := s.failures[endpoint]; 0 != len(queued) {
+			failure = &queued[0]
+			s.failures[endpoint] = queued[1:]
+		}
+		fixture := s.fixtures[endpoint]
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case nil != failure:
+			writeFailure(w, *failure)
+		case s.AppID != r.Form.Get("appID"):
+			writeFailure(w, Failure{Message: "Invalid appID"})
+		case nil != fixture:
+			w.Write(fixture)
+		default:
+			s.mu.Lock()
+			results, err := serve(r.Form)
+			s.mu.Unlock()
+
+			if nil != err {
+				writeFailure(w, Failure{Message: err.Error()})
+				return
+			}
+
+			body, err := marshal(map[string]interface{}{"resultSet": results})
+			if nil != err {
+				writeFailure(w, Failure{Status: http.StatusInternalServerError, Message: err.Error()})
+				return
+			}
+			w.Write(body)
+		}
+	}
+}
+
+// writeFailure writes f as an HTTP error or TriMet error message.
+func writeFailure(w http.ResponseWriter, f Failure) {
+	if 0 != f.Status && http.StatusOK != f.Status {
+		http.Error(w, f.Message, f.Status)
+		return
+	}
+
+	body, _ := marshal(map[string]interface{}{
+		"resultSet": map[string]interface{}{
+			"errorMessage": map[string]string{"content": f.Message},
+		},
+	})
+	w.Write(body)
+}
+
+// response returns the common fields of a response at the Server's time.
+// The caller must hold s.mu.
+func (s *Server) response() trimet.Response {
+	return trimet.Response{QueryTime: trimet.NewTime(s.now)}
+}
+
+// intList parses a comma separated list of integers.
+func intList(value string) ([]int, error) {
+	if "" == value {
+		return nil, nil
+	}
+
+	var ints []int
+	for _, field := range strings.Split(value, ",") {
+		var i int
+		if _, err := fmt.Sscan(field, &i); nil != err {
+			return nil, fmt.Errorf("Invalid number %q", field)
+		}
+		ints = append(ints, i)
+	}
+	return ints, nil
+}
+
+// floatList parses a comma separated list of floats.
+func floatList(value string) ([]float64, error) {
+	if "" == value {
+		return nil, nil
+	}
+
+	var floats []float64
+	for _, field := range strings.Split(value, ",") {
+		var f float64
+		if _, err := fmt.Sscan(field, &f); nil != err {
+			return nil, fmt.Errorf("Invalid number %q", field)
+		}
+		floats = append(floats, f)
+	}
+	return floats, nil
+}
+
+// containsInt reports whether ints contains i.
+func containsInt(ints []int, i int) bool {
+	for _, v := range ints {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
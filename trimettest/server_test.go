@@ -0,0 +1,272 @@
+package trimettest
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/juniorrobot/gotrimet"
+)
+
+const testAppID = `abc123`
+
+func newTestServer(t *testing.T) *Server {
+	s := NewServer(testAppID)
+	s.SetNow(time.Date(2014, 1, 12, 17, 12, 9, 0, time.FixedZone("PST", -8*60*60)))
+	return s
+}
+
+func TestServer_arrivals(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	s.AddLocation(trimet.Location{ID: 8989, Description: "NW 23rd & Marshall"})
+	s.AddArrival(trimet.Arrival{
+		Location:  8989,
+		Route:     15,
+		Block:     1537,
+		Status:    "estimated",
+		Scheduled: trimet.NewTime(s.Now().Add(10 * time.Minute)),
+		Estimated: trimet.NewTime(s.Now().Add(12 * time.Minute)),
+	})
+	s.AddArrival(trimet.Arrival{
+		Location:  8989,
+		Route:     15,
+		Block:     1538,
+		Status:    "scheduled",
+		Scheduled: trimet.NewTime(s.Now().Add(5 * time.Minute)),
+	})
+
+	c := s.Client()
+	arrivals, err := c.Arrivals.Get(&trimet.ArrivalsRequest{LocationIDs: []int{8989}})
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error: %v", err)
+	}
+
+	if 1 != len(arrivals.Locations) || "NW 23rd & Marshall" != arrivals.Locations[0].Description {
+		t.Errorf("Expected location 8989, found %+v", arrivals.Locations)
+	}
+	if 2 != len(arrivals.Arrivals) || 1538 != arrivals.Arrivals[0].Block {
+		t.Fatalf("Expected 2 arrivals ordered by time, found %+v", arrivals.Arrivals)
+	}
+	if !s.Now().Equal(*arrivals.QueryTime.Time) {
+		t.Errorf("Expected queryTime %v, found %v", s.Now(), arrivals.QueryTime)
+	}
+
+	if !s.CancelArrival(8989, 1537) {
+		t.Error("Expected CancelArrival to find block 1537")
+	}
+	s.Advance(6 * time.Minute)
+
+	arrivals, err = c.Arrivals.Get(&trimet.ArrivalsRequest{LocationIDs: []int{8989}})
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error: %v", err)
+	}
+	if 1 != len(arrivals.Arrivals) || "canceled" != arrivals.Arrivals[0].Status {
+		t.Errorf("Expected only canceled arrival to remain, found %+v", arrivals.Arrivals)
+	}
+}
+
+func TestServer_unknownLocation(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	_, err := s.Client().Arrivals.Get(&trimet.ArrivalsRequest{LocationIDs: []int{1}})
+	if !errors.Is(err, trimet.ErrUnknownLocation) {
+		t.Errorf("Expected ErrUnknownLocation, found %v", err)
+	}
+}
+
+func TestServer_appID(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	c := trimet.NewClient("wrong", nil)
+	c.BaseURL = s.Client().BaseURL
+
+	_, err := c.Detours.Get(&trimet.DetoursRequest{})
+	if !errors.Is(err, trimet.ErrInvalidAppID) {
+		t.Errorf("Expected ErrInvalidAppID, found %v", err)
+	}
+}
+
+func TestServer_Fail(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	s.Fail("detours", Failure{Status: http.StatusServiceUnavailable, Message: "down"})
+	c := s.Client()
+
+	if _, err := c.Detours.Get(&trimet.DetoursRequest{}); !errors.Is(err, trimet.ErrServerUnavailable) {
+		t.Errorf("Expected ErrServerUnavailable, found %v", err)
+	}
+	if _, err := c.Detours.Get(&trimet.DetoursRequest{}); nil != err {
+		t.Errorf("Expected failure to be consumed, found %v", err)
+	}
+}
+
+func TestServer_detours(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	s.AddDetour(trimet.Detour{ID: "1", Description: "a", Routes: []trimet.Route{{ID: 12}}})
+	s.AddDetour(trimet.Detour{ID: "2", Description: "b", Routes: []trimet.Route{{ID: 15}}})
+	c := s.Client()
+
+	detours, err := c.Detours.Get(&trimet.DetoursRequest{Routes: []int{15}})
+	if nil != err {
+		t.Fatalf("Detours.Get returned error: %v", err)
+	}
+	if 1 != len(detours.Detours) || "2" != detours.Detours[0].ID {
+		t.Errorf("Expected detour 2, found %+v", detours.Detours)
+	}
+
+	s.RemoveDetour("2")
+	detours, _ = c.Detours.Get(&trimet.DetoursRequest{Routes: []int{15}})
+	if 0 != len(detours.Detours) {
+		t.Errorf("Expected no detours, found %+v", detours.Detours)
+	}
+}
+
+func TestServer_routeConfig(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	s.AddRoute(trimet.Route{
+		ID:   193,
+		Type: "R",
+		Directions: []trimet.Direction{
+			{Number: 0, Locations: []trimet.Location{
+				{ID: 1, Sequence: 25, TimePoint: true},
+				{ID: 2, Sequence: 50},
+			}},
+			{Number: 1},
+		},
+	})
+
+	routes, err := s.Client().Routes.Get(&trimet.RouteConfigRequest{
+		Routes:     []int{193},
+		Direction:  "0",
+		TimePoints: "true",
+	})
+	if nil != err {
+		t.Fatalf("Routes.Get returned error: %v", err)
+	}
+
+	if 1 != len(routes.Routes) || 1 != len(routes.Routes[0].Directions) {
+		t.Fatalf("Expected route 193 with one direction, found %+v", routes.Routes)
+	}
+	if stops := routes.Routes[0].Directions[0].Locations; 1 != len(stops) || 1 != stops[0].ID {
+		t.Errorf("Expected time point stop 1, found %+v", stops)
+	}
+}
+
+func TestServer_stops(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	s.AddLocation(trimet.Location{ID: 10775, Lat: 45.5315030383606, Lon: -122.685356502158})
+	s.AddLocation(trimet.Location{ID: 8989, Lat: 45.5306116478909, Lon: -122.698688376761})
+
+	stops, err := s.Client().Stops.Get(&trimet.StopsRequest{
+		LonLat: []float64{-122.6861530, 45.5305500},
+		Feet:   500,
+	})
+	if nil != err {
+		t.Fatalf("Stops.Get returned error: %v", err)
+	}
+	if 1 != len(stops.Locations) || 10775 != stops.Locations[0].ID {
+		t.Errorf("Expected stop 10775, found %+v", stops.Locations)
+	}
+}
+
+func TestServer_vehicles(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	s.AddVehicle(trimet.Vehicle{ID: 2904, Route: 15, LocationTime: trimet.NewTime(s.Now())})
+	s.AddVehicle(trimet.Vehicle{ID: 3001, Route: 12, LocationTime: trimet.NewTime(s.Now().Add(-time.Minute))})
+	c := s.Client()
+
+	vehicles, err := c.Vehicles.Get(&trimet.VehiclesRequest{Routes: []int{15}})
+	if nil != err {
+		t.Fatalf("Vehicles.Get returned error: %v", err)
+	}
+	if 1 != len(vehicles.Vehicles) || 2904 != vehicles.Vehicles[0].ID {
+		t.Errorf("Expected vehicle 2904, found %+v", vehicles.Vehicles)
+	}
+
+	vehicles, _ = c.Vehicles.Get(&trimet.VehiclesRequest{Since: s.Now().Add(-time.Second)})
+	if 1 != len(vehicles.Vehicles) || 2904 != vehicles.Vehicles[0].ID {
+		t.Errorf("Expected only recently updated vehicle 2904, found %+v", vehicles.Vehicles)
+	}
+
+	s.RemoveVehicle(2904)
+	vehicles, _ = c.Vehicles.Get(&trimet.VehiclesRequest{Routes: []int{15}})
+	if 0 != len(vehicles.Vehicles) {
+		t.Errorf("Expected no vehicles, found %+v", vehicles.Vehicles)
+	}
+}
+
+func TestServer_LoadFixtures(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	if err := s.LoadFixtures("../testdata"); nil != err {
+		t.Fatalf("Unexpected error loading fixtures: %v", err)
+	}
+
+	arrivals, err := s.Client().Arrivals.Get(&trimet.ArrivalsRequest{LocationIDs: []int{8989}})
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error: %v", err)
+	}
+	if 1 != len(arrivals.Arrivals) || 1537 != arrivals.Arrivals[0].Block {
+		t.Errorf("Expected fixture arrival, found %+v", arrivals.Arrivals)
+	}
+
+	vehicles, err := s.Client().Vehicles.Get(&trimet.VehiclesRequest{})
+	if nil != err {
+		t.Fatalf("Vehicles.Get returned error: %v", err)
+	}
+	if 0 == len(vehicles.Vehicles) {
+		t.Error("Expected fixture vehicles, found none")
+	}
+	if requests := s.Requests(); "V2/vehicles" != requests[len(requests)-1].Endpoint {
+		t.Errorf("Expected V2/vehicles request, found %+v", requests[len(requests)-1])
+	}
+}
+
+func TestServer_Requests(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	s.Client().Detours.Get(&trimet.DetoursRequest{Routes: []int{12}})
+
+	requests := s.Requests()
+	if 1 != len(requests) {
+		t.Fatalf("Expected 1 recorded request, found %v", requests)
+	}
+	if "detours" != requests[0].Endpoint || "12" != requests[0].Query.Get("routes") {
+		t.Errorf("Expected detours request for route 12, found %+v", requests[0])
+	}
+
+	s.ResetRequests()
+	if 0 != len(s.Requests()) {
+		t.Error("Expected no requests after reset")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	at := time.Date(2014, 1, 12, 17, 12, 5, 0, time.FixedZone("PST", -8*60*60))
+	body, err := marshal(trimet.Position{At: trimet.NewTime(at), Feet: 100})
+	if nil != err {
+		t.Fatalf("Unexpected error marshaling: %v", err)
+	}
+
+	expect := `{"at":"2014-01-12T17:12:05.000-0800","feet":100,"heading":0,"lat":0,"lng":0}`
+	if !reflect.DeepEqual(expect, string(body)) {
+		t.Errorf("Expected %v, found %v", expect, string(body))
+	}
+}
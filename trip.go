@@ -2,25 +2,25 @@ package trimet
 
 type Trip struct {
 	// The trip number of this trip.
-	ID int `json:"tripNum"`
+	ID int `json:"tripNum" xml:"tripNum,attr"`
 
 	// The route's direction description of the trip.
-	Description string `json:"desc"`
+	Description string `json:"desc" xml:"desc,attr"`
 
 	// The number of feet along a trip the vehicle must traverse to arrive at
 	// a requested stop. If the vehicle must traverse the entire trip this
 	// number will always be the entire length of the trip.
-	Distance Distance `json:"destDist"`
+	Distance Distance `json:"destDist" xml:"destDist,attr"`
 
 	// The direction of the route of this trip.
-	Direction int `json:"dir"`
+	Direction int `json:"dir" xml:"dir,attr"`
 
 	// The pattern number for the trip.
-	Pattern int `json:"pattern"`
+	Pattern int `json:"pattern" xml:"pattern,attr"`
 
 	// The number of feet the vehicle has traversed along a trip's pattern.
-	Progress int `json:"progress"`
+	Progress int `json:"progress" xml:"progress,attr"`
 
 	// The route number for the related trip.
-	Route int `json:"route"`
+	Route int `json:"route" xml:"route,attr"`
 }
@@ -0,0 +1,368 @@
+package trimet
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	// Embedded so that trip planner times are in Portland time even where
+	// the system has no time zone database.
+	_ "time/tzdata"
+)
+
+// Trip planner modes of travel.
+const (
+	ModeAll   = "A"
+	ModeBus   = "B"
+	ModeTrain = "T"
+)
+
+// Trip planner optimizations.
+const (
+	OptimizeQuickest        = "T"
+	OptimizeFewestTransfers = "X"
+	OptimizeShortestWalk    = "W"
+)
+
+// TripPlannerService plans trips between two places using transit and
+// walking.
+//
+// The trip planner only reports results in XML, so requests are always made
+// in XML whether or not Request.XML is set.
+//
+// TriMet API docs: http://developer.trimet.org/ws_docs/tripplanner_ws.shtml
+type TripPlannerService struct {
+	client *Client
+}
+
+type TripPlannerRequest struct {
+	Request
+
+	// A place name or address to travel from.  Either FromPlace or FromCoord
+	// is required.
+	FromPlace string `url:"fromPlace,omitempty"`
+
+	// Longitude and latitude to travel from.
+	FromCoord []float64 `url:"fromCoord,omitempty,comma"`
+
+	// A place name or address to travel to.  Either ToPlace or ToCoord is
+	// required.
+	ToPlace string `url:"toPlace,omitempty"`
+
+	// Longitude and latitude to travel to.
+	ToCoord []float64 `url:"toCoord,omitempty,comma"`
+
+	// Time to depart, or to arrive by if Arrive is true.  If omitted the
+	// current time is used.
+	At time.Time `url:"-"`
+
+	// If true At is the time to arrive by rather than the time to depart.
+	Arrive bool `url:"-"`
+
+	// The modes of travel to use, one of ModeAll, ModeBus or ModeTrain.
+	// Defaults to ModeAll.
+	Mode string `url:"Mode,omitempty"`
+
+	// What to optimize for, one of OptimizeQuickest,
+	// OptimizeFewestTransfers or OptimizeShortestWalk.  Defaults to
+	// OptimizeQuickest.
+	Optimize string `url:"Min,omitempty"`
+
+	// The maximum distance to walk, in miles.
+	MaxWalk float64 `url:"Walk,omitempty"`
+
+	// If true only wheelchair accessible itineraries are planned.
+	Accessible bool `url:"wheelchair,omitempty"`
+}
+
+// wantsXML reports that trip plans are always requested in XML.
+func (r *TripPlannerRequest) wantsXML() bool {
+	return true
+}
+
+// Validate checks that the request has exactly one origin and destination
+// and a known mode and optimization.
+func (r *TripPlannerRequest) Validate() error {
+	v := new(ValidationError)
+	validateEndpoint(v, "From", r.FromPlace, r.FromCoord)
+	validateEndpoint(v, "To", r.ToPlace, r.ToCoord)
+
+	switch r.Mode {
+	case "", ModeAll, ModeBus, ModeTrain:
+	default:
+		v.add("Mode", fmt.Sprintf("unknown mode %q", r.Mode))
+	}
+	switch r.Optimize {
+	case "", OptimizeQuickest, OptimizeFewestTransfers, OptimizeShortestWalk:
+	default:
+		v.add("Optimize", fmt.Sprintf("unknown optimization %q", r.Optimize))
+	}
+	if r.MaxWalk < 0 {
+		v.add("MaxWalk", "must not be negative")
+	}
+	return v.err()
+}
+
+// validateEndpoint checks that exactly one of place or coord describes one
+// end of a trip.
+func validateEndpoint(v *ValidationError, name, place string, coord []float64) {
+	switch {
+	case "" == place && 0 == len(coord):
+		v.add(name+"Place", "either "+name+"Place or "+name+"Coord is required")
+	case "" != place && 0 != len(coord):
+		v.add(name+"Coord", "must not be combined with "+name+"Place")
+	case 0 != len(coord) && 2 != len(coord):
+		v.add(name+"Coord", "requires a longitude and latitude")
+	}
+}
+
+// tripPlannerParams adds the date and time parameters of a
+// TripPlannerRequest, which TriMet expects as separate local values.
+type tripPlannerParams struct {
+	*TripPlannerRequest
+
+	Date   string `url:"Date,omitempty"`
+	Time   string `url:"Time,omitempty"`
+	Arrive string `url:"Arr,omitempty"`
+}
+
+func newTripPlannerParams(r *TripPlannerRequest) *tripPlannerParams {
+	p := &tripPlannerParams{TripPlannerRequest: r}
+	if !r.At.IsZero() {
+		at := r.At.In(tripPlannerLocation)
+		p.Date = at.Format("01-02-2006")
+		p.Time = at.Format("3:04 PM")
+	}
+	if r.Arrive {
+		p.Arrive = "A"
+	}
+	return p
+}
+
+type TripPlannerResponse struct {
+	// Alternative itineraries for the trip, best first.
+	Itineraries []Itinerary
+}
+
+// Get plans a trip.
+func (s *TripPlannerService) Get(r *TripPlannerRequest) (*TripPlannerResponse, error) {
+	return s.GetContext(context.Background(), r)
+}
+
+// GetContext plans a trip, aborting if ctx is done.
+func (s *TripPlannerService) GetContext(ctx context.Context, r *TripPlannerRequest) (*TripPlannerResponse, error) {
+	if nil == r {
+		return nil, errors.New("Trip planner request must not be nil")
+	}
+
+	response := new(tripPlannerResponseResults)
+	err := s.client.GetContext(ctx, "trips/tripplanner", newTripPlannerParams(r), response)
+	if nil != err {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
+
+// tripPlannerLocation is the time zone in which the trip planner reports
+// and expects local times.
+var tripPlannerLocation = loadLocation("America/Los_Angeles")
+
+func loadLocation(name string) *time.Location {
+	l, err := time.LoadLocation(name)
+	if nil != err {
+		panic(fmt.Sprintf("trimet: unable to load time zone %s: %v", name, err))
+	}
+	return l
+}
+
+type tripPlannerResponseResults struct {
+	Results *TripPlannerResponse
+}
+
+// UnmarshalXML decodes the trip planner's response document, converting its
+// itineraries.
+func (r *tripPlannerResponseResults) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	doc := new(tripPlanXML)
+	if err := d.DecodeElement(doc, &start); nil != err {
+		return err
+	}
+
+	r.Results = new(TripPlannerResponse)
+	for _, i := range doc.Itineraries {
+		itinerary, err := i.itinerary()
+		if nil != err {
+			return err
+		}
+		r.Results.Itineraries = append(r.Results.Itineraries, itinerary)
+	}
+	return nil
+}
+
+// tripPlanXML is the trip planner's response document.
+type tripPlanXML struct {
+	Itineraries []itineraryXML `xml:"itineraries>itinerary"`
+}
+
+type itineraryXML struct {
+	ID           int             `xml:"id,attr"`
+	TimeDistance timeDistanceXML `xml:"time-distance"`
+	Fare         *Fare           `xml:"fare"`
+	Legs         []legXML        `xml:"leg"`
+}
+
+func (i *itineraryXML) itinerary() (Itinerary, error) {
+	td := i.TimeDistance
+	start, end, err := td.times("")
+	if nil != err {
+		return Itinerary{}, err
+	}
+
+	itinerary := Itinerary{
+		ID:          i.ID,
+		Start:       start,
+		End:         end,
+		Duration:    minutes(td.Duration),
+		Distance:    td.Distance,
+		Transfers:   td.Transfers,
+		WalkingTime: minutes(td.WalkingTime),
+		TransitTime: minutes(td.TransitTime),
+		WaitingTime: minutes(td.WaitingTime),
+		Fare:        i.Fare,
+	}
+	for _, l := range i.Legs {
+		leg, err := l.leg(td.Date)
+		if nil != err {
+			return Itinerary{}, err
+		}
+		itinerary.Legs = append(itinerary.Legs, leg)
+	}
+	return itinerary, nil
+}
+
+// timeDistanceXML describes the duration and distance of an itinerary or
+// leg.  Durations are in minutes and distances in miles.
+type timeDistanceXML struct {
+	Date        string  `xml:"date"`
+	StartTime   string  `xml:"startTime"`
+	EndTime     string  `xml:"endTime"`
+	Duration    int     `xml:"duration"`
+	Distance    float64 `xml:"distance"`
+	Transfers   int     `xml:"numberOfTransfers"`
+	WalkingTime int     `xml:"walkingTime"`
+	TransitTime int     `xml:"transitTime"`
+	WaitingTime int     `xml:"waitingTime"`
+}
+
+// times parses the start and end times, using date when no date is given.
+func (td *timeDistanceXML) times(date string) (start, end *Time, err error) {
+	if "" != td.Date {
+		date = td.Date
+	}
+	if start, err = parsePlannerTime(date, td.StartTime); nil != err {
+		return nil, nil, err
+	}
+	if end, err = parsePlannerTime(date, td.EndTime); nil != err {
+		return nil, nil, err
+	}
+	// Trips passing midnight end on the following day.
+	if nil != start && nil != end && end.Before(*start.Time) {
+		*end.Time = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+// plannerDateLayouts are the date formats reported by the trip planner.
+var plannerDateLayouts = []string{"1/2/06", "1/2/2006", "01-02-2006"}
+
+// parsePlannerTime parses a local date and clock time, such as "1/12/14" and
+// "5:42 PM".  It returns nil if either is missing.
+func parsePlannerTime(date, clock string) (*Time, error) {
+	date, clock = strings.TrimSpace(date), strings.TrimSpace(clock)
+	if "" == date || "" == clock {
+		return nil, nil
+	}
+
+	var err error
+	for _, layout := range plannerDateLayouts {
+		var t time.Time
+		t, err = time.ParseInLocation(layout+" 3:04 PM", date+" "+strings.ToUpper(clock), tripPlannerLocation)
+		if nil == err {
+			return NewTime(t), nil
+		}
+	}
+	return nil, err
+}
+
+func minutes(n int) time.Duration {
+	return time.Duration(n) * time.Minute
+}
+
+type legXML struct {
+	Mode         string          `xml:"mode,attr"`
+	TimeDistance timeDistanceXML `xml:"time-distance"`
+	From         placeXML        `xml:"from"`
+	To           placeXML        `xml:"to"`
+	Route        *legRouteXML    `xml:"route"`
+	Steps        []Step          `xml:"path>step"`
+}
+
+func (l *legXML) leg(date string) (Leg, error) {
+	start, end, err := l.TimeDistance.times(date)
+	if nil != err {
+		return Leg{}, err
+	}
+
+	leg := Leg{
+		Mode:     l.Mode,
+		Start:    start,
+		End:      end,
+		Duration: minutes(l.TimeDistance.Duration),
+		Distance: l.TimeDistance.Distance,
+		From:     l.From.location(),
+		To:       l.To.location(),
+		Steps:    l.Steps,
+	}
+	if nil != l.Route {
+		leg.Route = &Route{
+			ID:          l.Route.Number,
+			Description: l.Route.Name,
+			Type:        routeType(l.Mode),
+		}
+		leg.Block = l.Route.Block
+	}
+	return leg, nil
+}
+
+// routeType returns the Route type of a transit leg mode.
+func routeType(mode string) string {
+	if LegBus == mode {
+		return "B"
+	}
+	return "R"
+}
+
+type placeXML struct {
+	Description string  `xml:"description"`
+	Lat         float64 `xml:"pos>lat"`
+	Lon         float64 `xml:"pos>lon"`
+	StopID      int     `xml:"stopId"`
+}
+
+func (p *placeXML) location() *Location {
+	return &Location{
+		ID:          p.StopID,
+		Description: p.Description,
+		Lat:         p.Lat,
+		Lon:         p.Lon,
+	}
+}
+
+type legRouteXML struct {
+	Number int    `xml:"internalNumber"`
+	Name   string `xml:"name"`
+	Block  int    `xml:"block"`
+}
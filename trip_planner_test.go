@@ -0,0 +1,141 @@
+package trimet
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTripPlannerService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/trips/tripplanner", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testHeader(t, r, "Accept", xmlMediaType)
+		testFormValues(t, r, values{
+			"appID":     testAppID,
+			"fromCoord": "-122.6985,45.5294",
+			"toPlace":   "Pioneer Square",
+			"Date":      "01-12-2014",
+			"Time":      "5:12 PM",
+			"Arr":       "A",
+			"Mode":      ModeBus,
+			"Walk":      "0.5",
+		})
+		b, err := ioutil.ReadFile("testdata/tripplanner.xml")
+		if nil != err {
+			t.Fatal("Unable to read testdata/tripplanner.xml")
+		}
+		w.Write(b)
+	})
+
+	req := &TripPlannerRequest{
+		FromCoord: []float64{-122.6985, 45.5294},
+		ToPlace:   "Pioneer Square",
+		At:        time.Date(2014, 1, 13, 1, 12, 0, 0, time.UTC),
+		Arrive:    true,
+		Mode:      ModeBus,
+		MaxWalk:   0.5,
+	}
+	plan, err := client.TripPlanner.Get(req)
+	if err != nil {
+		t.Fatalf("TripPlanner.Get returned error: %v", err)
+	}
+
+	at := func(hour, min int) *Time {
+		return NewTime(time.Date(2014, 1, 12, hour, min, 0, 0, tripPlannerLocation))
+	}
+	marshall := &Location{ID: 8989, Description: "NW 23rd & Marshall", Lat: 45.5306116478909, Lon: -122.698688376761}
+	expect := &TripPlannerResponse{
+		Itineraries: []Itinerary{
+			{
+				ID:          1,
+				Start:       at(17, 14),
+				End:         at(17, 36),
+				Duration:    22 * time.Minute,
+				Distance:    2.31,
+				WalkingTime: 4 * time.Minute,
+				TransitTime: 16 * time.Minute,
+				WaitingTime: 2 * time.Minute,
+				Fare:        &Fare{Regular: 2.50, Honored: 1.00, Youth: 1.65},
+				Legs: []Leg{
+					{
+						Mode:     LegWalk,
+						Start:    at(17, 14),
+						End:      at(17, 18),
+						Duration: 4 * time.Minute,
+						Distance: 0.18,
+						From:     &Location{Description: "NW 23rd & Lovejoy", Lat: 45.5294, Lon: -122.6985},
+						To:       marshall,
+						Steps: []Step{
+							{Street: "NW 23rd Ave", RelativeDirection: "start", AbsoluteDirection: "north", Distance: 0.18},
+						},
+					},
+					{
+						Mode:     LegBus,
+						Start:    at(17, 20),
+						End:      at(17, 36),
+						Duration: 16 * time.Minute,
+						Distance: 2.13,
+						From:     marshall,
+						To:       &Location{ID: 4016, Description: "SW Morrison & 4th", Lat: 45.5189, Lon: -122.6768},
+						Route:    &Route{ID: 15, Description: "15-Belmont/NW 23rd", Type: "B"},
+						Block:    1537,
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(plan, expect) {
+		t.Errorf("Expected TripPlanner.Get to return:\n%+v\nfound:\n%+v", expect, plan)
+	}
+	if plan.Itineraries[0].Legs[0].IsTransit() || !plan.Itineraries[0].Legs[1].IsTransit() {
+		t.Error("Expected only the bus leg to be transit")
+	}
+}
+
+func TestTripPlannerService_Get_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/trips/tripplanner", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<response success="false"><error><message>Origin is invalid</message></error></response>`))
+	})
+
+	_, err := client.TripPlanner.Get(&TripPlannerRequest{FromPlace: "nowhere", ToPlace: "Pioneer Square"})
+	if !errors.Is(err, ErrMalformedParameters) {
+		t.Errorf("Expected ErrMalformedParameters, found %v", err)
+	}
+}
+
+func TestParsePlannerTime_midnight(t *testing.T) {
+	td := &timeDistanceXML{Date: "1/12/14", StartTime: "11:50 PM", EndTime: "12:10 AM"}
+	start, end, err := td.times("")
+	if nil != err {
+		t.Fatalf("Unexpected error parsing times: %v", err)
+	}
+	if 20*time.Minute != end.Sub(*start.Time) {
+		t.Errorf("Expected trip ending after midnight to last 20m, found %v", end.Sub(*start.Time))
+	}
+}
+
+func TestTripPlannerRequest_Validate(t *testing.T) {
+	testValidationFields(t, (&TripPlannerRequest{FromPlace: "a", ToPlace: "b"}).Validate())
+	testValidationFields(t, (&TripPlannerRequest{}).Validate(), "FromPlace", "ToPlace")
+	testValidationFields(t, (&TripPlannerRequest{
+		FromPlace: "a",
+		FromCoord: []float64{1, 2},
+		ToCoord:   []float64{1},
+	}).Validate(), "FromCoord", "ToCoord")
+	testValidationFields(t, (&TripPlannerRequest{
+		FromPlace: "a",
+		ToPlace:   "b",
+		Mode:      "X",
+		Optimize:  "Q",
+		MaxWalk:   -1,
+	}).Validate(), "Mode", "Optimize", "MaxWalk")
+}
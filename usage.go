@@ -0,0 +1,64 @@
+package trimet
+
+import (
+	"strings"
+	"sync"
+)
+
+// usage counts the requests sent by a Client to each endpoint.  The zero
+// value is ready to use, so that Clients not created by NewClient count
+// requests too.
+type usage struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (u *usage) add(endpoint string) {
+	u.mu.Lock()
+	if nil == u.counts {
+		u.counts = make(map[string]int)
+	}
+	u.counts[endpoint]++
+	u.mu.Unlock()
+}
+
+func (u *usage) snapshot() map[string]int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	counts := make(map[string]int, len(u.counts))
+	for k, v := range u.counts {
+		counts[k] = v
+	}
+	return counts
+}
+
+func (u *usage) reset() {
+	u.mu.Lock()
+	u.counts = nil
+	u.mu.Unlock()
+}
+
+// Usage returns the number of requests sent to each endpoint, such as
+// "arrivals" or "routeConfig", since the Client was created or ResetUsage was
+// last called.  Every attempt counts, including retries.
+func (c *Client) Usage() map[string]int {
+	return c.usage.snapshot()
+}
+
+// ResetUsage clears the request counts reported by Usage.
+func (c *Client) ResetUsage() {
+	c.usage.reset()
+}
+
+// endpoint returns the name of the endpoint path relative to the BaseURL.
+// Version 2 endpoints are prefixed with "v2/".
+func (c *Client) endpoint(path string) string {
+	if nil != c.BaseURLV2 && strings.HasPrefix(path, c.BaseURLV2.Path) && "/" != c.BaseURLV2.Path {
+		return "v2/" + strings.Trim(strings.TrimPrefix(path, c.BaseURLV2.Path), "/")
+	}
+	if nil != c.BaseURL {
+		path = strings.TrimPrefix(path, c.BaseURL.Path)
+	}
+	return strings.Trim(path, "/")
+}
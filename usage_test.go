@@ -0,0 +1,63 @@
+package trimet
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Usage(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultSet":{}}`)
+	})
+
+	client.Arrivals.Get(&ArrivalsRequest{LocationIDs: []int{8989}})
+	client.Arrivals.Get(&ArrivalsRequest{LocationIDs: []int{10775}})
+	client.Routes.Get(&RouteConfigRequest{})
+	client.Stops.Get(&StopsRequest{LonLat: []float64{-122.68, 45.53}, Feet: 500})
+	client.Detours.Get(&DetoursRequest{})
+
+	expect := map[string]int{
+		"arrivals":    2,
+		"routeConfig": 1,
+		"stops":       1,
+		"detours":     1,
+	}
+	if usage := client.Usage(); !reflect.DeepEqual(expect, usage) {
+		t.Errorf("Expected Usage() = %v, found %v", expect, usage)
+	}
+
+	client.ResetUsage()
+	if usage := client.Usage(); 0 != len(usage) {
+		t.Errorf("Expected empty Usage() after reset, found %v", usage)
+	}
+}
+
+func TestClient_endpoint(t *testing.T) {
+	c := NewClient(testAppID, nil)
+	if e := c.endpoint("/ws/V1/arrivals"); "arrivals" != e {
+		t.Errorf("Expected endpoint = arrivals, found %v", e)
+	}
+}
+
+func TestClient_Usage_literal(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultSet":{}}`)
+	})
+
+	c := &Client{client: http.DefaultClient, appID: "abc123", BaseURL: client.BaseURL}
+	c.Arrivals = &ArrivalsService{client: c}
+	if _, err := c.Arrivals.Get(&ArrivalsRequest{LocationIDs: []int{8989}}); nil != err {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if usage := c.Usage(); 1 != usage["arrivals"] {
+		t.Errorf("Expected 1 arrivals request, found %v", usage)
+	}
+}
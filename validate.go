@@ -0,0 +1,73 @@
+package trimet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A FieldError reports an invalid field of a request.
+type FieldError struct {
+	// Name of the invalid request field, such as "LocationIDs".
+	Field string
+
+	// Description of the problem.
+	Message string
+
+	// Class of error, such as ErrTooManyLocations.  Defaults to
+	// ErrMalformedParameters.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func (e *FieldError) Unwrap() error {
+	if nil == e.Err {
+		return ErrMalformedParameters
+	}
+	return e.Err
+}
+
+// A ValidationError reports the invalid fields of a request, found before it
+// was sent.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Error()
+	}
+	return "Invalid request: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of the invalid fields.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f
+	}
+	return errs
+}
+
+// add records an invalid field.
+func (e *ValidationError) add(field, message string) *FieldError {
+	f := &FieldError{Field: field, Message: message}
+	e.Fields = append(e.Fields, f)
+	return f
+}
+
+// err returns e if any invalid fields were recorded, or nil otherwise.
+func (e *ValidationError) err() error {
+	if 0 == len(e.Fields) {
+		return nil
+	}
+	return e
+}
+
+// validator is implemented by requests which can check their own fields.
+type validator interface {
+	Validate() error
+}
@@ -0,0 +1,89 @@
+package trimet
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testValidationFields checks that err reports invalid fields in order.
+func testValidationFields(t *testing.T, err error, expect ...string) {
+	if 0 == len(expect) {
+		if nil != err {
+			t.Errorf("Unexpected validation error: %v", err)
+		}
+		return
+	}
+
+	var v *ValidationError
+	if !errors.As(err, &v) {
+		t.Errorf("Expected *ValidationError for fields %v, found %v", expect, err)
+		return
+	}
+
+	var fields []string
+	for _, f := range v.Fields {
+		fields = append(fields, f.Field)
+	}
+	if !reflect.DeepEqual(expect, fields) {
+		t.Errorf("Expected invalid fields %v, found %v (%v)", expect, fields, err)
+	}
+}
+
+func TestArrivalsRequest_Validate(t *testing.T) {
+	testValidationFields(t, (&ArrivalsRequest{LocationIDs: []int{8989}}).Validate())
+	testValidationFields(t, (&ArrivalsRequest{}).Validate(), "LocationIDs")
+	testValidationFields(t, (&ArrivalsRequest{LocationIDs: []int{-1}}).Validate(), "LocationIDs")
+
+	err := (&ArrivalsRequest{LocationIDs: locationIDRange(1, 11)}).Validate()
+	testValidationFields(t, err, "LocationIDs")
+	if !errors.Is(err, ErrTooManyLocations) {
+		t.Errorf("Expected %v to match ErrTooManyLocations", err)
+	}
+	if errors.Is(err, ErrMalformedParameters) {
+		t.Errorf("Expected %v not to match ErrMalformedParameters", err)
+	}
+}
+
+func TestDetoursRequest_Validate(t *testing.T) {
+	testValidationFields(t, (&DetoursRequest{}).Validate())
+	testValidationFields(t, (&DetoursRequest{Routes: []int{12, -4}}).Validate(), "Routes")
+}
+
+func TestRouteConfigRequest_Validate(t *testing.T) {
+	for _, dir := range []string{"", "0", "1", "true", "yes"} {
+		testValidationFields(t, (&RouteConfigRequest{Direction: dir}).Validate())
+	}
+	testValidationFields(t, (&RouteConfigRequest{Direction: "both"}).Validate(), "Direction")
+	testValidationFields(t, (&RouteConfigRequest{StartSequence: 10}).Validate())
+	testValidationFields(t, (&RouteConfigRequest{StartSequence: 10, EndSequence: 20}).Validate())
+	testValidationFields(t, (&RouteConfigRequest{StartSequence: 20, EndSequence: 10}).Validate(), "StartSequence")
+	testValidationFields(t, (&RouteConfigRequest{EndSequence: -1}).Validate(), "EndSequence")
+}
+
+func TestStopsRequest_Validate(t *testing.T) {
+	testValidationFields(t, (&StopsRequest{BoundingBox: []float64{-122.7, 45.5, -122.6, 45.6}}).Validate())
+	testValidationFields(t, (&StopsRequest{LonLat: []float64{-122.68, 45.53}, Meters: 100}).Validate())
+
+	testValidationFields(t, (&StopsRequest{}).Validate(), "BoundingBox")
+	testValidationFields(t, (&StopsRequest{BoundingBox: []float64{1, 2, 3}}).Validate(), "BoundingBox")
+	testValidationFields(t, (&StopsRequest{BoundingBox: []float64{-122.6, 45.5, -122.7, 45.6}}).Validate(), "BoundingBox")
+	testValidationFields(t, (&StopsRequest{BoundingBox: []float64{-122.7, 45.5, -122.6, 45.6}, Feet: 5}).Validate(), "Feet")
+	testValidationFields(t, (&StopsRequest{LonLat: []float64{-122.68, 45.53}}).Validate(), "Feet")
+	testValidationFields(t, (&StopsRequest{LonLat: []float64{-122.68, 45.53}, Feet: 5, Meters: 5}).Validate(), "Feet")
+	testValidationFields(t, (&StopsRequest{LonLat: []float64{-122.68}, Feet: 5}).Validate(), "LonLat")
+	testValidationFields(t, (&StopsRequest{
+		BoundingBox: []float64{-122.7, 45.5, -122.6, 45.6},
+		LonLat:      []float64{-122.68, 45.53},
+		Feet:        5,
+	}).Validate(), "BoundingBox")
+}
+
+func TestNewRequest_invalidParams(t *testing.T) {
+	c := NewClient(testAppID, nil)
+
+	_, err := c.NewRequest("GET", "arrivals", &ArrivalsRequest{})
+	if !errors.Is(err, ErrMalformedParameters) {
+		t.Errorf("Expected ErrMalformedParameters, found %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package trimet
+
+// A Vehicle contains the last reported location of a vehicle in service.
+type Vehicle struct {
+	// The vehicle's identifier.
+	ID int `json:"vehicleID"`
+
+	// The block the vehicle is serving.
+	Block int `json:"blockID"`
+
+	// The trip the vehicle is serving.
+	TripID string `json:"tripID"`
+
+	// The route number the vehicle is serving.
+	Route int `json:"routeNumber"`
+
+	// The direction of the route, either 1 for inbound or 0 for outbound.
+	Direction int `json:"direction"`
+
+	// The type of the vehicle, either "bus" or "rail".
+	Type string `json:"type"`
+
+	// The latitude of the vehicle at its last reported location.
+	Lat float64 `json:"latitude"`
+
+	// The longitude of the vehicle at its last reported location.
+	Lon float64 `json:"longitude"`
+
+	// The heading of the vehicle in degrees.
+	Bearing int `json:"bearing"`
+
+	// The short text of the overhead sign of the vehicle.
+	SignMessage string `json:"signMessage"`
+
+	// The full text of the overhead sign of the vehicle.
+	SignMessageLong string `json:"signMessageLong"`
+
+	// Number of seconds the vehicle is behind schedule.  Negative values
+	// indicate the vehicle is ahead of schedule.
+	Delay int `json:"delay"`
+
+	// The location IDs of the last stop served and the next to be served.
+	LastLocation int `json:"lastLocID"`
+	NextLocation int `json:"nextLocID"`
+
+	// How full the vehicle is, as a percentage of its capacity.
+	LoadPercentage int `json:"loadPercentage"`
+
+	// Indicates the vehicle is traveling in congested traffic.
+	InCongestion bool `json:"inCongestion"`
+
+	// The time the vehicle's location was last updated.
+	LocationTime *Time `json:"time"`
+
+	// The time after which this location should be considered stale.
+	Expires *Time `json:"expires"`
+}
@@ -0,0 +1,75 @@
+package trimet
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// VehiclesService reports the locations of all vehicles in service, or those
+// serving particular routes.
+//
+// TriMet API docs: http://developer.trimet.org/ws_docs/vehicle_locations_ws.shtml
+type VehiclesService struct {
+	client *Client
+}
+
+type VehiclesRequest struct {
+	Request
+
+	// If present results will contain only vehicles serving these routes.
+	Routes []int `url:"routes,omitempty,comma"`
+
+	// If present results will contain only these vehicles.
+	VehicleIDs []int `url:"ids,omitempty,comma"`
+
+	// If set, only vehicles whose location was updated after this time
+	// are reported.
+	Since time.Time `url:"since,omitempty,unixmilli"`
+}
+
+type VehiclesResponse struct {
+	Response
+	Vehicles []Vehicle `json:"vehicle"`
+}
+
+type vehiclesResponseResults struct {
+	Results *VehiclesResponse `json:"resultSet,omitempty"`
+}
+
+// Validate checks the request's route numbers and vehicle IDs.
+func (r *VehiclesRequest) Validate() error {
+	v := new(ValidationError)
+	for _, route := range r.Routes {
+		if route < 0 {
+			v.add("Routes", fmt.Sprintf("invalid route number %d", route))
+		}
+	}
+	for _, id := range r.VehicleIDs {
+		if id <= 0 {
+			v.add("VehicleIDs", fmt.Sprintf("invalid vehicle ID %d", id))
+		}
+	}
+	return v.err()
+}
+
+// Get latest vehicle locations.
+func (s *VehiclesService) Get(r *VehiclesRequest) (*VehiclesResponse, error) {
+	return s.GetContext(context.Background(), r)
+}
+
+// GetContext gets latest vehicle locations, aborting if ctx is done.
+func (s *VehiclesService) GetContext(ctx context.Context, r *VehiclesRequest) (*VehiclesResponse, error) {
+	u, err := s.client.BaseURLV2.Parse("vehicles")
+	if nil != err {
+		return nil, err
+	}
+
+	response := new(vehiclesResponseResults)
+	err = s.client.GetContext(ctx, u.String(), r, response)
+	if nil != err {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
@@ -0,0 +1,104 @@
+package trimet
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVehiclesService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/V2/vehicles", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"appID":  testAppID,
+			"json":   "true",
+			"routes": "15",
+			"since":  "1389575500000",
+		})
+		b, err := ioutil.ReadFile("testdata/V2/vehicles.json")
+		if nil != err {
+			t.Fatal("Unable to read testdata/V2/vehicles.json")
+		}
+		w.Write(b)
+	})
+
+	req := &VehiclesRequest{
+		Routes: []int{15},
+		Since:  time.Unix(1389575500, 0),
+	}
+	vehicles, err := client.Vehicles.Get(req)
+	if err != nil {
+		t.Fatalf("Vehicles.Get returned error: %v", err)
+	}
+
+	ms := func(ms int64) *Time {
+		return NewTime(time.Unix(0, ms*int64(time.Millisecond)))
+	}
+	expect := &VehiclesResponse{
+		Response: Response{QueryTime: ms(1389575529351)},
+		Vehicles: []Vehicle{
+			{
+				ID:              2904,
+				Block:           1537,
+				TripID:          "4285964",
+				Route:           15,
+				Direction:       1,
+				Type:            "bus",
+				Lat:             45.5233678,
+				Lon:             -122.6973469,
+				Bearing:         273,
+				SignMessage:     "15 Gateway TC",
+				SignMessageLong: "15  Belmont/NW 23rd to Gateway TC",
+				Delay:           -45,
+				LastLocation:    8981,
+				NextLocation:    8989,
+				LoadPercentage:  42,
+				InCongestion:    true,
+				LocationTime:    ms(1389575525000),
+				Expires:         ms(1389577325000),
+			},
+		},
+	}
+	if !reflect.DeepEqual(vehicles, expect) {
+		t.Errorf("Expected Vehicles.Get to return:\n%+v\nfound:\n%+v", expect, vehicles)
+	}
+}
+
+func TestVehiclesService_Get_jsonOnly(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/V2/vehicles", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{
+			"appID": testAppID,
+			"json":  "true",
+		})
+		testHeader(t, r, "Accept", mediaType)
+		b, err := ioutil.ReadFile("testdata/V2/vehicles.json")
+		if nil != err {
+			t.Fatal("Unable to read testdata/V2/vehicles.json")
+		}
+		w.Write(b)
+	})
+
+	req := &VehiclesRequest{}
+	req.XML = true
+	response, err := client.Vehicles.Get(req)
+	if nil != err {
+		t.Fatalf("Vehicles.Get returned error: %v", err)
+	}
+	if nil == response || 0 == len(response.Vehicles) {
+		t.Errorf("Expected vehicles decoded from JSON, found %+v", response)
+	}
+}
+
+func TestVehiclesRequest_Validate(t *testing.T) {
+	testValidationFields(t, (&VehiclesRequest{}).Validate())
+	testValidationFields(t, (&VehiclesRequest{VehicleIDs: []int{0}}).Validate(), "VehicleIDs")
+	testValidationFields(t, (&VehiclesRequest{Routes: []int{-1}}).Validate(), "Routes")
+}
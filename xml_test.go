@@ -0,0 +1,202 @@
+package trimet
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// serveFixture registers a handler for endpoint which serves the JSON or XML
+// fixture depending on the requested format.
+func serveFixture(t *testing.T, endpoint string) {
+	mux.HandleFunc("/"+endpoint, func(w http.ResponseWriter, r *http.Request) {
+		ext, accept := "xml", xmlMediaType
+		if "true" == r.FormValue("json") {
+			ext, accept = "json", mediaType
+		}
+		testHeader(t, r, "Accept", accept)
+
+		name := fmt.Sprintf("testdata/%s.%s", endpoint, ext)
+		b, err := ioutil.ReadFile(name)
+		if nil != err {
+			t.Fatalf("Unable to read %v", name)
+		}
+		w.Write(b)
+	})
+}
+
+func TestArrivalsService_Get_xmlParity(t *testing.T) {
+	setup()
+	defer teardown()
+	serveFixture(t, "arrivals")
+
+	fromJSON, err := client.Arrivals.Get(&ArrivalsRequest{LocationIDs: []int{8989}})
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error for JSON: %v", err)
+	}
+
+	req := &ArrivalsRequest{LocationIDs: []int{8989}}
+	req.XML = true
+	fromXML, err := client.Arrivals.Get(req)
+	if nil != err {
+		t.Fatalf("Arrivals.Get returned error for XML: %v", err)
+	}
+
+	if !reflect.DeepEqual(fromJSON, fromXML) {
+		t.Errorf("Expected XML response to match JSON:\n%+v\nfound:\n%+v", fromJSON, fromXML)
+	}
+}
+
+func TestDetoursService_Get_xmlParity(t *testing.T) {
+	setup()
+	defer teardown()
+	serveFixture(t, "detours")
+
+	fromJSON, err := client.Detours.Get(&DetoursRequest{})
+	if nil != err {
+		t.Fatalf("Detours.Get returned error for JSON: %v", err)
+	}
+
+	req := &DetoursRequest{}
+	req.XML = true
+	fromXML, err := client.Detours.Get(req)
+	if nil != err {
+		t.Fatalf("Detours.Get returned error for XML: %v", err)
+	}
+
+	if !reflect.DeepEqual(fromJSON, fromXML) {
+		t.Errorf("Expected XML response to match JSON:\n%+v\nfound:\n%+v", fromJSON, fromXML)
+	}
+}
+
+func TestRoutesService_Get_xmlParity(t *testing.T) {
+	setup()
+	defer teardown()
+	serveFixture(t, "routeConfig")
+
+	fromJSON, err := client.Routes.Get(&RouteConfigRequest{})
+	if nil != err {
+		t.Fatalf("Routes.Get returned error for JSON: %v", err)
+	}
+
+	req := &RouteConfigRequest{}
+	req.XML = true
+	fromXML, err := client.Routes.Get(req)
+	if nil != err {
+		t.Fatalf("Routes.Get returned error for XML: %v", err)
+	}
+
+	if !reflect.DeepEqual(fromJSON, fromXML) {
+		t.Errorf("Expected XML response to match JSON:\n%+v\nfound:\n%+v", fromJSON, fromXML)
+	}
+}
+
+func TestStopsService_Get_xmlParity(t *testing.T) {
+	setup()
+	defer teardown()
+	serveFixture(t, "stops")
+
+	fromJSON, err := client.Stops.Get(&StopsRequest{LonLat: []float64{-122.68, 45.53}, Feet: 500})
+	if nil != err {
+		t.Fatalf("Stops.Get returned error for JSON: %v", err)
+	}
+
+	req := &StopsRequest{LonLat: []float64{-122.68, 45.53}, Feet: 500}
+	req.XML = true
+	fromXML, err := client.Stops.Get(req)
+	if nil != err {
+		t.Fatalf("Stops.Get returned error for XML: %v", err)
+	}
+
+	if !reflect.DeepEqual(fromJSON, fromXML) {
+		t.Errorf("Expected XML response to match JSON:\n%+v\nfound:\n%+v", fromJSON, fromXML)
+	}
+}
+
+func TestNewRequest_xml(t *testing.T) {
+	c := NewClient(testAppID, nil)
+
+	params := &ArrivalsRequest{LocationIDs: []int{8989}}
+	params.XML = true
+	req, err := c.NewRequest("GET", "arrivals", params)
+	if nil != err {
+		t.Fatalf("Unexpected error creating request: %v", err)
+	}
+
+	outURL := defaultBaseURL + "arrivals?appID=" + testAppID + "&locIDs=8989"
+	if req.URL.String() != outURL {
+		t.Errorf("Expected NewRequest URL = %v, found %v", outURL, req.URL)
+	}
+}
+
+func TestNewRequest_jsonFormat(t *testing.T) {
+	c := NewClient(testAppID, nil)
+
+	for _, test := range []struct {
+		JSON   bool
+		XML    bool
+		Query  string
+		Accept string
+	}{
+		{false, false, "&json=true", mediaType},
+		{true, false, "&json=true", mediaType},
+		{false, true, "", xmlMediaType},
+		{true, true, "", xmlMediaType},
+	} {
+		params := &ArrivalsRequest{LocationIDs: []int{8989}}
+		params.JSON = test.JSON
+		params.XML = test.XML
+		req, err := c.NewRequest("GET", "arrivals", params)
+		if nil != err {
+			t.Fatalf("Unexpected error creating request: %v", err)
+		}
+
+		outURL := defaultBaseURL + "arrivals?appID=" + testAppID + test.Query + "&locIDs=8989"
+		if req.URL.String() != outURL {
+			t.Errorf("Expected NewRequest URL = %v, found %v", outURL, req.URL)
+		}
+		if accept := req.Header.Get("Accept"); test.Accept != accept {
+			t.Errorf("Expected Accept %v, found %v", test.Accept, accept)
+		}
+	}
+}
+
+func TestDo_xmlError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?>`+
+			`<resultSet xmlns="urn:trimet:arrivals">`+
+			`<errorMessage>Location id not found 99999</errorMessage>`+
+			`</resultSet>`)
+	})
+
+	req := &ArrivalsRequest{LocationIDs: []int{99999}}
+	req.XML = true
+	_, err := client.Arrivals.Get(req)
+
+	if !errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("Expected ErrUnknownLocation, found %v", err)
+	}
+}
+
+func TestDo_nonXML(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultSet":{}}`)
+	})
+
+	req := &ArrivalsRequest{LocationIDs: []int{8989}}
+	req.XML = true
+	_, err := client.Arrivals.Get(req)
+
+	if !errors.Is(err, ErrNonXMLResponse) {
+		t.Errorf("Expected ErrNonXMLResponse, found %v", err)
+	}
+}